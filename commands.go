@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+// commands maps CLI subcommands to their implementation
+var commands = map[string]func(args []string) error{
+	"export": runExport,
+	"import": runImport,
+}
+
+// loadCLIConfig loads the supervisord config from a path or by auto-detection
+func loadCLIConfig(configPath string) (*supervisor.Config, error) {
+	if configPath == "" {
+		path, err := supervisor.FindConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		configPath = path
+	}
+	return supervisor.LoadConfig(configPath)
+}
+
+// selectPrograms returns the named programs, or all programs if no names are given
+func selectPrograms(config *supervisor.Config, names []string) ([]*supervisor.ProcessConfig, error) {
+	if len(names) == 0 {
+		return config.Programs, nil
+	}
+
+	var progs []*supervisor.ProcessConfig
+	for _, name := range names {
+		prog := config.GetProcessConfig(name)
+		if prog == nil {
+			return nil, fmt.Errorf("program not found: %s", name)
+		}
+		progs = append(progs, prog)
+	}
+	return progs, nil
+}
+
+// runExport implements `god export [-format yaml|json|systemd|procfile] [-o path] [program...]`
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to supervisord config file (default: auto-detect)")
+	format := fs.String("format", "", "Output format: yaml, json, systemd or procfile (default: from -o name, else yaml)")
+	output := fs.String("o", "", "Write to file instead of stdout (a directory for one unit per program with -format systemd)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: god export [flags] [program...]")
+		fmt.Fprintln(fs.Output(), "Exports the given programs (default: all) as YAML, JSON, systemd units or a Procfile.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	config, err := loadCLIConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	progs, err := selectPrograms(config, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = supervisor.FormatFromPath(*output)
+	}
+
+	// Units for several programs go into a directory, one file each
+	if *format == supervisor.FormatSystemd && *output != "" && !strings.HasSuffix(*output, ".service") {
+		notes, err := supervisor.ExportSystemdUnits(progs, *output)
+		printExportNotes(notes)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d unit(s) to %s\n", len(progs), *output)
+		return nil
+	}
+
+	data, notes, err := supervisor.ExportPrograms(progs, *format)
+	if err != nil {
+		return err
+	}
+	printExportNotes(notes)
+
+	if *output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d program(s) to %s\n", len(progs), *output)
+	return nil
+}
+
+// printExportNotes reports settings that couldn't be converted
+func printExportNotes(notes []string) {
+	if len(notes) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Settings without an equivalent:")
+	for _, note := range notes {
+		fmt.Fprintf(os.Stderr, "  %s\n", note)
+	}
+}
+
+// runImport implements `god import [-format yaml|json] [-yes] [-dry-run] file`
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to supervisord config file (default: auto-detect)")
+	format := fs.String("format", "", "Input format: yaml or json (default: from file extension)")
+	yes := fs.Bool("yes", false, "Apply without asking for confirmation")
+	dryRun := fs.Bool("dry-run", false, "Only show what would change")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: god import [flags] file")
+		fmt.Fprintln(fs.Output(), "Imports programs from a YAML or JSON file ('-' for stdin) and applies them.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected exactly one file")
+	}
+	path := fs.Arg(0)
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if *format == "" {
+		*format = supervisor.FormatFromPath(path)
+	}
+
+	progs, err := supervisor.ImportPrograms(data, *format)
+	if err != nil {
+		return err
+	}
+
+	config, err := loadCLIConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	changes := supervisor.PlanImport(config, progs)
+	pending := printImportPlan(changes)
+	if pending == 0 {
+		fmt.Println("Nothing to do.")
+		return nil
+	}
+	if *dryRun {
+		return nil
+	}
+
+	if !*yes {
+		if path == "-" {
+			return fmt.Errorf("refusing to prompt while reading from stdin, use -yes")
+		}
+		fmt.Print("Apply these changes? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	names, err := supervisor.ApplyImport(changes)
+	if supervisor.IsPermissionError(err) {
+		return fmt.Errorf("%w (try again with sudo)", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	client := supervisor.NewClient()
+	if _, err := client.Reread(); err != nil {
+		return err
+	}
+	if err := client.Update(names...); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d program(s).\n", len(names))
+	return nil
+}
+
+// printImportPlan prints a preview of an import and returns the number of pending changes
+func printImportPlan(changes []*supervisor.ImportChange) int {
+	pending := 0
+	for _, change := range changes {
+		switch change.Action {
+		case "create":
+			pending++
+			fmt.Printf("+ %s (new)\n", change.Program.Name)
+		case "change":
+			pending++
+			fmt.Printf("~ %s (changed)\n", change.Program.Name)
+			for _, line := range strings.Split(strings.TrimSuffix(change.Diff, "\n"), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		default:
+			fmt.Printf("= %s (unchanged)\n", change.Program.Name)
+		}
+	}
+	return pending
+}
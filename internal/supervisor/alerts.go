@@ -0,0 +1,178 @@
+package supervisor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Alert severities
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// AlertRule raises an alert when a program writes a matching line to its log
+type AlertRule struct {
+	Name     string   // Name from the [alert:name] header
+	Programs []string // Program names or globs like "worker*"; "*" matches all programs
+	Pattern  *regexp.Regexp
+	Stream   string // "stdout" or "stderr", empty for both
+	Severity string // SeverityCritical, SeverityWarning or SeverityInfo
+	Command  string // Run with sh -c when the rule matches, empty for none
+	Cooldown time.Duration
+}
+
+// AlertsFile returns the file alert rules are read from
+func AlertsFile() string {
+	return filepath.Join(ConfigDir(), "alerts.conf")
+}
+
+// LoadAlertRules reads the alert rules from AlertsFile()
+// A missing file means there are no rules. Rules look like:
+//
+//	[alert:oom]
+//	program=worker*
+//	pattern=OutOfMemoryError|Killed process
+//	stream=stderr
+//	severity=critical
+//	command=notify-send "$GOD_PROGRAM" "$GOD_LINE"
+func LoadAlertRules() ([]*AlertRule, error) {
+	return loadAlertRules(AlertsFile())
+}
+
+// loadAlertRules reads the alert rules from a file
+func loadAlertRules(file string) ([]*AlertRule, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	var rules []*AlertRule
+	for _, s := range scanSections(lines) {
+		if s.Kind != "alert" {
+			continue
+		}
+		rule, err := parseAlertRule(s, lines)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: [alert:%s]: %w", file, s.StartLine, s.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseAlertRule parses the keys of an [alert:name] section
+func parseAlertRule(s section, lines []string) (*AlertRule, error) {
+	rule := &AlertRule{
+		Name:     s.Name,
+		Programs: []string{"*"},
+		Severity: SeverityWarning,
+		Cooldown: time.Minute,
+	}
+
+	// Lines are 1-based, the header is skipped
+	for _, line := range lines[s.StartLine:s.EndLine] {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected key=value, got %q", line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "program", "programs":
+			rule.Programs = nil
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					rule.Programs = append(rule.Programs, name)
+				}
+			}
+		case "pattern":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern: %w", err)
+			}
+			rule.Pattern = re
+		case "stream":
+			switch value {
+			case "stdout", "stderr":
+				rule.Stream = value
+			case "", "both":
+				rule.Stream = ""
+			default:
+				return nil, fmt.Errorf("stream must be stdout, stderr or both, not %q", value)
+			}
+		case "severity":
+			switch value {
+			case SeverityCritical, SeverityWarning, SeverityInfo:
+				rule.Severity = value
+			default:
+				return nil, fmt.Errorf("severity must be critical, warning or info, not %q", value)
+			}
+		case "command":
+			rule.Command = value
+		case "cooldown":
+			cooldown, err := parseCooldown(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Cooldown = cooldown
+		default:
+			return nil, fmt.Errorf("unknown key %q", key)
+		}
+	}
+
+	if rule.Pattern == nil {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	return rule, nil
+}
+
+// parseCooldown parses a duration like "5m", or a number of seconds
+func parseCooldown(value string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid cooldown %q", value)
+	}
+	return d, nil
+}
+
+// MatchesProcess returns true if the rule applies to a process
+// A process "group:name" is matched by its full name, its group and its name.
+func (r *AlertRule) MatchesProcess(name string) bool {
+	candidates := []string{name}
+	if group, process, ok := strings.Cut(name, ":"); ok {
+		candidates = append(candidates, group, process)
+	}
+	for _, pattern := range r.Programs {
+		for _, candidate := range candidates {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchesStream returns true if the rule applies to lines of a stream
+func (r *AlertRule) MatchesStream(stream string) bool {
+	return r.Stream == "" || r.Stream == stream
+}
@@ -0,0 +1,133 @@
+package supervisor
+
+import (
+	"bufio"
+	"sort"
+	"strings"
+)
+
+// Kinds of pending config changes
+const (
+	ChangeAdded   = "added"   // Defined on disk, not yet added to supervisord
+	ChangeChanged = "changed" // Definition on disk differs from the running one
+	ChangeRemoved = "removed" // Running, but no longer defined on disk
+)
+
+// ConfigChanges maps program group names to the kind of pending change
+// These are differences between the config on disk and what supervisord runs,
+// applied by `supervisorctl update`
+type ConfigChanges map[string]string
+
+// Names returns the affected group names in sorted order
+func (c ConfigChanges) Names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// For returns the pending change of the group a process belongs to
+// Processes of a group with numprocs > 1 are named "group:process"
+func (c ConfigChanges) For(processName string) string {
+	if kind, ok := c[processName]; ok {
+		return kind
+	}
+	if group, _, ok := strings.Cut(processName, ":"); ok {
+		return c[group]
+	}
+	return ""
+}
+
+// AvailEntry is a program group listed by `supervisorctl avail`
+type AvailEntry struct {
+	Name      string
+	InUse     bool // Added to supervisord (as opposed to only available)
+	Autostart bool
+	Priority  string
+}
+
+// PendingChanges returns program groups whose config on disk hasn't been applied
+// It combines `reread` with `avail`, which also lists groups reread earlier
+// (e.g. by another supervisorctl session) that were never added.
+// It is polled in the background, so it never escalates through sudo.
+func (c *Client) PendingChanges() (ConfigChanges, error) {
+	output, err := c.supervisorctlUnprivileged("reread")
+	if err != nil {
+		return nil, commandError("failed to reread config", output, err)
+	}
+	changes := parseReread(string(output))
+
+	output, err = c.supervisorctlUnprivileged("avail")
+	if err != nil {
+		return nil, commandError("failed to list available programs", output, err)
+	}
+	for _, entry := range parseAvail(string(output)) {
+		if !entry.InUse && changes[entry.Name] == "" {
+			changes[entry.Name] = ChangeAdded
+		}
+	}
+
+	return changes, nil
+}
+
+// parseReread parses the output of `supervisorctl reread`:
+//
+//	myapp: changed
+//	newapp: available
+//	oldapp: disappeared
+func parseReread(output string) ConfigChanges {
+	changes := make(ConfigChanges)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		name, state, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ": ")
+		if !ok {
+			continue // e.g. "No config updates to processes"
+		}
+		switch strings.TrimSpace(state) {
+		case "available":
+			changes[name] = ChangeAdded
+		case "changed":
+			changes[name] = ChangeChanged
+		case "disappeared":
+			changes[name] = ChangeRemoved
+		}
+	}
+	return changes
+}
+
+// parseAvail parses the output of `supervisorctl avail`:
+//
+//	myapp                            in use    auto      999:999
+//	newapp                           avail     manual    999:999
+func parseAvail(output string) []AvailEntry {
+	var entries []AvailEntry
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		entry := AvailEntry{Name: fields[0]}
+		rest := fields[1:]
+		switch {
+		case len(rest) >= 2 && rest[0] == "in" && rest[1] == "use":
+			entry.InUse = true
+			rest = rest[2:]
+		case rest[0] == "avail":
+			rest = rest[1:]
+		default:
+			continue
+		}
+		if len(rest) > 0 {
+			entry.Autostart = rest[0] == "auto"
+		}
+		if len(rest) > 1 {
+			entry.Priority = rest[1]
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -196,7 +197,11 @@ func DetectSocketPath() string {
 	if err != nil {
 		return "unix:///tmp/supervisor.sock" // Default fallback
 	}
+	return SocketPath(configPath)
+}
 
+// SocketPath returns the socket path set in the [unix_http_server] section of a config file
+func SocketPath(configPath string) string {
 	file, err := os.Open(configPath)
 	if err != nil {
 		return "unix:///tmp/supervisor.sock"
@@ -248,54 +253,81 @@ func DetectSocketPath() string {
 
 // Start starts a process
 func (c *Client) Start(name string) error {
-	cmd := exec.Command("supervisorctl", "start", name)
-	output, err := cmd.CombinedOutput()
+	output, err := c.supervisorctl("start", name)
 	if err != nil {
-		return fmt.Errorf("failed to start %s: %s", name, string(output))
+		return commandError(fmt.Sprintf("failed to start %s", name), output, err)
 	}
 	return nil
 }
 
 // Stop stops a process
 func (c *Client) Stop(name string) error {
-	cmd := exec.Command("supervisorctl", "stop", name)
-	output, err := cmd.CombinedOutput()
+	output, err := c.supervisorctl("stop", name)
 	if err != nil {
-		return fmt.Errorf("failed to stop %s: %s", name, string(output))
+		return commandError(fmt.Sprintf("failed to stop %s", name), output, err)
 	}
 	return nil
 }
 
 // Restart restarts a process
 func (c *Client) Restart(name string) error {
-	cmd := exec.Command("supervisorctl", "restart", name)
-	output, err := cmd.CombinedOutput()
+	output, err := c.supervisorctl("restart", name)
 	if err != nil {
-		return fmt.Errorf("failed to restart %s: %s", name, string(output))
+		return commandError(fmt.Sprintf("failed to restart %s", name), output, err)
 	}
 	return nil
 }
 
 // Reread tells supervisord to reread config files
-func (c *Client) Reread() error {
-	cmd := exec.Command("supervisorctl", "reread")
-	output, err := cmd.CombinedOutput()
+// It returns the program groups whose config changed on disk
+func (c *Client) Reread() (ConfigChanges, error) {
+	output, err := c.supervisorctl("reread")
 	if err != nil {
-		return fmt.Errorf("failed to reread config: %s", string(output))
+		return nil, commandError("failed to reread config", output, err)
 	}
-	return nil
+	return parseReread(string(output)), nil
 }
 
-// Update updates process configurations
-func (c *Client) Update(name string) error {
-	args := []string{"update"}
-	if name != "" {
-		args = append(args, name)
-	}
-	cmd := exec.Command("supervisorctl", args...)
-	output, err := cmd.CombinedOutput()
+// Update applies config changes for the given program groups (all groups if none are given)
+func (c *Client) Update(names ...string) error {
+	args := append([]string{"update"}, names...)
+	output, err := c.supervisorctl(args...)
 	if err != nil {
-		return fmt.Errorf("failed to update %s: %s", name, string(output))
+		target := strings.Join(names, ", ")
+		if target == "" {
+			target = "programs"
+		}
+		return commandError(fmt.Sprintf("failed to update %s", target), output, err)
 	}
 	return nil
 }
+
+// supervisorctl runs a supervisorctl command that changes state
+// If it fails for lack of permissions it is retried through sudo once enabled,
+// otherwise the returned error wraps fs.ErrPermission
+func (c *Client) supervisorctl(args ...string) ([]byte, error) {
+	output, err := c.supervisorctlUnprivileged(args...)
+	if IsPermissionError(err) && SudoEnabled() {
+		return runSudo("supervisorctl", args...)
+	}
+	return output, err
+}
+
+// supervisorctlUnprivileged runs a supervisorctl command as the current user only
+// Background polls use it, so they never run sudo. Errors caused by missing
+// permissions wrap fs.ErrPermission.
+func (c *Client) supervisorctlUnprivileged(args ...string) ([]byte, error) {
+	output, err := exec.Command("supervisorctl", args...).CombinedOutput()
+	if supervisorctlPermissionDenied(output) {
+		return output, fs.ErrPermission
+	}
+	return output, err
+}
+
+// commandError formats a failed supervisorctl command, keeping permission errors detectable
+func commandError(msg string, output []byte, err error) error {
+	if IsPermissionError(err) {
+		return fmt.Errorf("%s: %s: %w", msg, strings.TrimSpace(string(output)), err)
+	}
+	return fmt.Errorf("%s: %s", msg, string(output))
+}
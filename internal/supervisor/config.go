@@ -2,20 +2,23 @@ package supervisor
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // Config represents a supervisord configuration file
 type Config struct {
-	Path     string
-	Programs []*ProcessConfig
-	RawLines []string
+	Path        string
+	Programs    []*ProcessConfig
+	RawLines    []string
+	Supervisord map[string]string // Options of the [supervisord] section
 }
 
 // FindConfigFile finds the supervisord config file
@@ -92,9 +95,10 @@ func getConfigFromSupervisorctl() string {
 // It also loads configs from /etc/supervisor/conf.d/ if they exist
 func LoadConfig(path string) (*Config, error) {
 	config := &Config{
-		Path:     path,
-		Programs: []*ProcessConfig{},
-		RawLines: []string{},
+		Path:        path,
+		Programs:    []*ProcessConfig{},
+		RawLines:    []string{},
+		Supervisord: make(map[string]string),
 	}
 
 	// Load main config file
@@ -103,13 +107,7 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	// Also load configs from conf.d directory
-	confDirs := []string{
-		"/etc/supervisor/conf.d",
-		"/etc/supervisord.d",
-		filepath.Join(filepath.Dir(path), "conf.d"),
-	}
-
-	for _, confDir := range confDirs {
+	for _, confDir := range confDirs(path) {
 		if err := loadConfigsFromDir(confDir, config); err == nil {
 			// Successfully loaded from this directory
 			break
@@ -119,6 +117,34 @@ func LoadConfig(path string) (*Config, error) {
 	return config, nil
 }
 
+// confDirs are the directories searched for program configs besides the config file at path
+func confDirs(path string) []string {
+	return []string{
+		"/etc/supervisor/conf.d",
+		"/etc/supervisord.d",
+		filepath.Join(filepath.Dir(path), "conf.d"),
+	}
+}
+
+// ConfigStamp returns a fingerprint of the config file at path and the files in
+// its config directories. It changes when any of them is edited, added or removed,
+// without reading them.
+func ConfigStamp(path string) string {
+	paths := []string{path}
+	for _, dir := range confDirs(path) {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.conf"))
+		paths = append(append(paths, dir), files...)
+	}
+
+	var sb strings.Builder
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil {
+			fmt.Fprintf(&sb, "%s %d %d\n", p, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return sb.String()
+}
+
 // loadConfigFile loads a single config file
 func loadConfigFile(path string, config *Config) error {
 	file, err := os.Open(path)
@@ -127,13 +153,22 @@ func loadConfigFile(path string, config *Config) error {
 	}
 	defer file.Close()
 
+	// Remember where programs come from so edits can be written back in place
+	sourceFile := path
+	if absPath, err := filepath.Abs(path); err == nil {
+		sourceFile = absPath
+	}
+
 	scanner := bufio.NewScanner(file)
 	var currentProgram *ProcessConfig
 	var inProgramSection bool
+	var inSupervisordSection bool
+	lineNum := 0
 
 	for scanner.Scan() {
 		line := scanner.Text()
 		config.RawLines = append(config.RawLines, line)
+		lineNum++
 
 		trimmed := strings.TrimSpace(line)
 
@@ -158,6 +193,16 @@ func loadConfigFile(path string, config *Config) error {
 			continue
 		}
 
+		// Remember the [supervisord] options, e.g. childlogdir for AUTO logfiles
+		if strings.HasPrefix(trimmed, "[") {
+			inSupervisordSection = trimmed == "[supervisord]"
+		} else if inSupervisordSection && config.Supervisord != nil {
+			if key, value, ok := strings.Cut(trimmed, "="); ok {
+				config.Supervisord[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+			continue
+		}
+
 		// Check for [program:name] section
 		if strings.HasPrefix(trimmed, "[program:") && strings.HasSuffix(trimmed, "]") {
 			// Save previous program if exists
@@ -170,10 +215,14 @@ func loadConfigFile(path string, config *Config) error {
 			name = strings.TrimSuffix(name, "]")
 
 			currentProgram = &ProcessConfig{
-				Name:        name,
-				Environment: make(map[string]string),
-				Autostart:   false,
-				Autorestart: false,
+				Name:              name,
+				Environment:       make(map[string]string),
+				Extra:             make(map[string]string),
+				Autostart:         true, // supervisord defaults
+				RestartUnexpected: true,
+				SourceFile:        sourceFile,
+				StartLine:         lineNum,
+				EndLine:           lineNum,
 			}
 			inProgramSection = true
 			continue
@@ -192,6 +241,7 @@ func loadConfigFile(path string, config *Config) error {
 		// Parse program configuration
 		if inProgramSection && currentProgram != nil {
 			parseProgramLine(trimmed, currentProgram)
+			currentProgram.EndLine = lineNum
 		}
 	}
 
@@ -260,7 +310,7 @@ func parseProgramLine(line string, config *ProcessConfig) {
 	case "autostart":
 		config.Autostart = strings.ToLower(value) == "true"
 	case "autorestart":
-		config.Autorestart = strings.ToLower(value) == "true"
+		config.SetAutorestart(value)
 	case "startsecs":
 		if i, err := strconv.Atoi(value); err == nil {
 			config.StartSecs = i
@@ -297,14 +347,26 @@ func parseProgramLine(line string, config *ProcessConfig) {
 		if i, err := strconv.Atoi(value); err == nil {
 			config.StopWaitSecs = i
 		}
+	default:
+		// Keep options we don't model so they survive a rewrite
+		if config.Extra != nil {
+			config.Extra[key] = value
+		}
 	}
 }
 
+// bytesRe matches byte values like "1MB", "500KB" or "1024"
+var bytesRe = regexp.MustCompile(`^(\d+)(KB|MB|GB)?$`)
+
+// validBytes returns true if value is a byte value parseBytes understands
+func validBytes(value string) bool {
+	return bytesRe.MatchString(strings.TrimSpace(strings.ToUpper(value)))
+}
+
 // parseBytes parses byte values like "1MB", "500KB", etc.
 func parseBytes(value string) int64 {
 	value = strings.TrimSpace(strings.ToUpper(value))
-	re := regexp.MustCompile(`^(\d+)(KB|MB|GB)?$`)
-	matches := re.FindStringSubmatch(value)
+	matches := bytesRe.FindStringSubmatch(value)
 	if len(matches) < 2 {
 		return 0
 	}
@@ -431,108 +493,295 @@ func FindConfDDir() (string, error) {
 	return "/etc/supervisor/conf.d", nil
 }
 
-// SaveProcessConfig saves a single process config to conf.d/{process-name}.conf
+// SaveProcessConfig saves a single process config
+// Programs loaded from a file are written back to their section in that file,
+// new programs are saved to conf.d/{process-name}.conf
 func SaveProcessConfig(prog *ProcessConfig) error {
+	if prog.SourceFile != "" {
+		return rewriteProgramSection(prog, func(current []string) string {
+			return mergeProgramSection(current, prog)
+		})
+	}
+
 	confDir, err := FindConfDDir()
 	if err != nil {
 		return fmt.Errorf("failed to find conf.d directory: %w", err)
 	}
 
 	// Ensure conf.d directory exists
-	if err := os.MkdirAll(confDir, 0755); err != nil {
-		return fmt.Errorf("failed to create conf.d directory: %w", err)
+	if err := makeConfigDir(confDir); err != nil {
+		return err
 	}
 
 	// Save to conf.d/{process-name}.conf
 	configPath := filepath.Join(confDir, prog.Name+".conf")
-	file, err := os.Create(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to create config file: %w", err)
+	return writeConfigFile(configPath, programSectionText(prog))
+}
+
+// RenameProcessConfig saves prog in place of the program called oldName
+// A program defined in {old-name}.conf moves to conf.d/{new-name}.conf,
+// otherwise the section is renamed where it is defined
+func RenameProcessConfig(oldName string, prog *ProcessConfig) error {
+	if prog.SourceFile == "" || filepath.Base(prog.SourceFile) != oldName+".conf" {
+		return SaveProcessConfig(prog)
 	}
-	defer file.Close()
 
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
+	confDir, err := FindConfDDir()
+	if err != nil {
+		return fmt.Errorf("failed to find conf.d directory: %w", err)
+	}
+	target := filepath.Join(confDir, prog.Name+".conf")
+	previous, err := readFileIfExists(target)
+	if err != nil {
+		return err
+	}
 
-	writeProgramSection(writer, prog)
+	// Write the new file first, so a failure leaves the old program untouched
+	renamed := *prog
+	renamed.SourceFile = ""
+	renamed.StartLine = 0
+	renamed.EndLine = 0
+	if err := SaveProcessConfig(&renamed); err != nil {
+		return err
+	}
 
+	old := *prog
+	old.Name = oldName
+	if err := DeleteProcessConfig(&old); err != nil {
+		// Put the new file back the way it was, so the program isn't defined twice
+		if previous == "" {
+			deleteConfigFile(target)
+		} else {
+			writeConfigFile(target, previous)
+		}
+		return err
+	}
 	return nil
 }
 
-// DeleteProcessConfig deletes a process config file from conf.d
-func DeleteProcessConfig(processName string) error {
+// DeleteProcessConfig removes a program's section from the file it is defined in
+// Files left without any section are deleted
+func DeleteProcessConfig(prog *ProcessConfig) error {
+	if prog.SourceFile != "" {
+		return replaceProgramSection(prog, "")
+	}
+
+	// Without a known source, look for conf.d/{process-name}.conf
 	confDir, err := FindConfDDir()
 	if err != nil {
 		return fmt.Errorf("failed to find conf.d directory: %w", err)
 	}
 
-	configPath := filepath.Join(confDir, processName+".conf")
+	configPath := filepath.Join(confDir, prog.Name+".conf")
+	content, err := readFileIfExists(configPath)
+	if err != nil {
+		return err
+	}
+
+	span, ok := findSection(splitLines(content), "program", prog.Name)
+	if !ok {
+		return fmt.Errorf("no config found for %s", prog.Name)
+	}
+
+	located := *prog
+	located.SourceFile = configPath
+	located.StartLine = span.StartLine
+	located.EndLine = span.EndLine
+	return replaceProgramSection(&located, "")
+}
+
+// replaceProgramSection replaces the lines of prog's section in its source file
+// with text. An empty text removes the section.
+func replaceProgramSection(prog *ProcessConfig, text string) error {
+	return rewriteProgramSection(prog, func([]string) string { return text })
+}
+
+// rewriteProgramSection replaces the lines of prog's section in its source file
+// with the text render returns for the current lines of the section
+func rewriteProgramSection(prog *ProcessConfig, render func(current []string) string) error {
+	content, err := readFileIfExists(prog.SourceFile)
+	if err != nil {
+		return err
+	}
+	lines := splitLines(content)
+
+	// Make sure the file still looks the way it did when it was loaded
+	span, ok := sectionAt(lines, prog.StartLine)
+	if !ok || span.Kind != "program" || span.EndLine != prog.EndLine {
+		return fmt.Errorf("%s changed on disk since it was loaded, reload and try again", prog.SourceFile)
+	}
+	text := render(lines[span.StartLine-1 : span.EndLine])
 
-	// Check if file exists before trying to delete
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// File doesn't exist, that's okay - maybe it was in main config
-		return nil
+	var result []string
+	result = append(result, lines[:span.StartLine-1]...)
+	result = append(result, splitLines(text)...)
+	rest := lines[span.EndLine:]
+	if text == "" {
+		// Drop the blank lines that separated the removed section
+		for len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+			rest = rest[1:]
+		}
 	}
+	result = append(result, rest...)
 
-	if err := os.Remove(configPath); err != nil {
-		return fmt.Errorf("failed to delete config file: %w", err)
+	// Delete files that no longer define anything
+	if text == "" && len(scanSections(result)) == 0 {
+		return deleteConfigFile(prog.SourceFile)
 	}
 
-	return nil
+	return writeConfigFile(prog.SourceFile, strings.Join(result, "\n")+"\n")
 }
 
-// writeProgramSection writes a [program:name] section
-func writeProgramSection(writer *bufio.Writer, prog *ProcessConfig) {
-	writer.WriteString(fmt.Sprintf("[program:%s]\n", prog.Name))
+// programSectionText renders a [program:name] section
+func programSectionText(prog *ProcessConfig) string {
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	writeProgramSection(writer, prog)
+	writer.Flush()
+	return buf.String()
+}
+
+// option is a key=value line of a config section
+type option struct {
+	key   string
+	value string
+}
+
+// programOptions returns the options of a [program:name] section in the order they are written
+func programOptions(prog *ProcessConfig) []option {
+	var opts []option
+	add := func(key, value string) {
+		opts = append(opts, option{key, value})
+	}
 
 	if prog.Command != "" {
-		writer.WriteString(fmt.Sprintf("command=%s\n", prog.Command))
+		add("command", prog.Command)
 	}
 	if prog.Directory != "" {
-		writer.WriteString(fmt.Sprintf("directory=%s\n", prog.Directory))
+		add("directory", prog.Directory)
 	}
 	if prog.User != "" {
-		writer.WriteString(fmt.Sprintf("user=%s\n", prog.User))
+		add("user", prog.User)
 	}
-	writer.WriteString(fmt.Sprintf("autostart=%v\n", prog.Autostart))
-	writer.WriteString(fmt.Sprintf("autorestart=%v\n", prog.Autorestart))
+	add("autostart", strconv.FormatBool(prog.Autostart))
+	add("autorestart", prog.AutorestartValue())
 	if prog.StartSecs > 0 {
-		writer.WriteString(fmt.Sprintf("startsecs=%d\n", prog.StartSecs))
+		add("startsecs", strconv.Itoa(prog.StartSecs))
 	}
 	if prog.StartRetries > 0 {
-		writer.WriteString(fmt.Sprintf("startretries=%d\n", prog.StartRetries))
+		add("startretries", strconv.Itoa(prog.StartRetries))
 	}
 	if prog.StdoutLogfile != "" {
-		writer.WriteString(fmt.Sprintf("stdout_logfile=%s\n", prog.StdoutLogfile))
+		add("stdout_logfile", prog.StdoutLogfile)
 	}
 	if prog.StderrLogfile != "" {
-		writer.WriteString(fmt.Sprintf("stderr_logfile=%s\n", prog.StderrLogfile))
+		add("stderr_logfile", prog.StderrLogfile)
 	}
 	if prog.StdoutLogfileMaxBytes > 0 {
-		writer.WriteString(fmt.Sprintf("stdout_logfile_maxbytes=%s\n", formatBytes(prog.StdoutLogfileMaxBytes)))
+		add("stdout_logfile_maxbytes", formatBytes(prog.StdoutLogfileMaxBytes))
 	}
 	if prog.StdoutLogfileBackups > 0 {
-		writer.WriteString(fmt.Sprintf("stdout_logfile_backups=%d\n", prog.StdoutLogfileBackups))
+		add("stdout_logfile_backups", strconv.Itoa(prog.StdoutLogfileBackups))
 	}
 	if prog.StderrLogfileMaxBytes > 0 {
-		writer.WriteString(fmt.Sprintf("stderr_logfile_maxbytes=%s\n", formatBytes(prog.StderrLogfileMaxBytes)))
+		add("stderr_logfile_maxbytes", formatBytes(prog.StderrLogfileMaxBytes))
 	}
 	if prog.StderrLogfileBackups > 0 {
-		writer.WriteString(fmt.Sprintf("stderr_logfile_backups=%d\n", prog.StderrLogfileBackups))
+		add("stderr_logfile_backups", strconv.Itoa(prog.StderrLogfileBackups))
 	}
 	if len(prog.Environment) > 0 {
-		envStr := formatEnvironment(prog.Environment)
-		writer.WriteString(fmt.Sprintf("environment=%s\n", envStr))
+		add("environment", formatEnvironment(prog.Environment))
 	}
 	if prog.Priority > 0 {
-		writer.WriteString(fmt.Sprintf("priority=%d\n", prog.Priority))
+		add("priority", strconv.Itoa(prog.Priority))
 	}
 	if prog.StopSignal != "" {
-		writer.WriteString(fmt.Sprintf("stopsignal=%s\n", prog.StopSignal))
+		add("stopsignal", prog.StopSignal)
 	}
 	if prog.StopWaitSecs > 0 {
-		writer.WriteString(fmt.Sprintf("stopwaitsecs=%d\n", prog.StopWaitSecs))
+		add("stopwaitsecs", strconv.Itoa(prog.StopWaitSecs))
+	}
+	for _, key := range SortedKeys(prog.Extra) {
+		add(key, prog.Extra[key])
+	}
+	return opts
+}
+
+// writeProgramSection writes a [program:name] section
+func writeProgramSection(writer *bufio.Writer, prog *ProcessConfig) {
+	writer.WriteString(fmt.Sprintf("[program:%s]\n", prog.Name))
+	for _, opt := range programOptions(prog) {
+		writer.WriteString(fmt.Sprintf("%s=%s\n", opt.key, opt.value))
+	}
+}
+
+// mergeProgramSection renders prog over the current lines of its section
+// Only options whose value changed are rewritten, so comments, key order and
+// spellings of the same value (e.g. autostart=yes) are kept
+func mergeProgramSection(current []string, prog *ProcessConfig) string {
+	// Render the current section the same way, to compare values
+	loaded := &ProcessConfig{
+		Environment:       make(map[string]string),
+		Extra:             make(map[string]string),
+		Autostart:         true, // supervisord defaults
+		RestartUnexpected: true,
+	}
+	for _, line := range current[1:] {
+		parseProgramLine(strings.TrimSpace(line), loaded)
+	}
+	before := make(map[string]string)
+	for _, opt := range programOptions(loaded) {
+		before[opt.key] = opt.value
+	}
+	opts := programOptions(prog)
+	after := make(map[string]string)
+	for _, opt := range opts {
+		after[opt.key] = opt.value
+	}
+
+	result := []string{fmt.Sprintf("[program:%s]", prog.Name)}
+	written := make(map[string]bool)
+	for _, line := range current[1:] {
+		trimmed := strings.TrimSpace(line)
+		key, _, ok := strings.Cut(trimmed, "=")
+		key = strings.TrimSpace(key)
+		if !ok || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#") {
+			result = append(result, line)
+			continue
+		}
+
+		value, keep := after[key]
+		_, known := before[key]
+		switch {
+		case !known && !keep:
+			result = append(result, line) // Not understood, leave it alone
+		case !keep, written[key]:
+			// Removed, or a repeated key that was rewritten already
+		case value != before[key]:
+			result = append(result, fmt.Sprintf("%s=%s", key, value))
+		default:
+			result = append(result, line)
+		}
+		written[key] = true
 	}
+
+	// Add options that weren't set before, unless they only restate a default
+	for _, opt := range opts {
+		if !written[opt.key] && opt.value != before[opt.key] {
+			result = append(result, fmt.Sprintf("%s=%s", opt.key, opt.value))
+		}
+	}
+	return strings.Join(result, "\n") + "\n"
+}
+
+// SortedKeys returns the keys of a map in sorted order
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // formatBytes formats bytes to string like "1MB"
@@ -550,8 +799,100 @@ func formatBytes(bytes int64) string {
 // formatEnvironment formats environment map to string
 func formatEnvironment(env map[string]string) string {
 	var pairs []string
-	for k, v := range env {
-		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	for _, k := range SortedKeys(env) {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, env[k]))
 	}
 	return strings.Join(pairs, ",")
 }
+
+// CloneProgram returns a copy of prog under a new name for which inUse is false
+// Log files named after the program are renamed to match the new name, and the
+// copy isn't tied to prog's file so it is saved to conf.d/{new-name}.conf.
+func CloneProgram(prog *ProcessConfig, inUse func(name string) bool) *ProcessConfig {
+	clone := *prog
+	clone.Name = uniqueProgramName(prog.Name, inUse)
+	clone.SourceFile = ""
+	clone.StartLine = 0
+	clone.EndLine = 0
+
+	clone.Environment = make(map[string]string, len(prog.Environment))
+	for key, value := range prog.Environment {
+		clone.Environment[key] = value
+	}
+	clone.Extra = make(map[string]string, len(prog.Extra))
+	for key, value := range prog.Extra {
+		clone.Extra[key] = value
+	}
+
+	clone.StdoutLogfile = cloneLogfile(prog.StdoutLogfile, prog.Name, clone.Name)
+	clone.StderrLogfile = cloneLogfile(prog.StderrLogfile, prog.Name, clone.Name)
+	return &clone
+}
+
+// uniqueProgramName derives an unused program name from name:
+// worker-1 becomes worker-2, web becomes web-2
+func uniqueProgramName(name string, inUse func(name string) bool) string {
+	base, n := name, 1
+	if i := strings.LastIndexAny(name, "-_"); i >= 0 {
+		if num, err := strconv.Atoi(name[i+1:]); err == nil {
+			base, n = name[:i], num
+		}
+	}
+	sep := "-"
+	if strings.HasPrefix(name[len(base):], "_") {
+		sep = "_"
+	}
+
+	for {
+		n++
+		candidate := fmt.Sprintf("%s%s%d", base, sep, n)
+		if !inUse(candidate) {
+			return candidate
+		}
+	}
+}
+
+// cloneLogfile rewrites a log file path of a program for its clone
+// AUTO, NONE and syslog are kept, since they don't point to a shared file
+func cloneLogfile(path, oldName, newName string) string {
+	switch strings.ToUpper(path) {
+	case "", "AUTO", "NONE", "SYSLOG":
+		return path
+	}
+
+	dir, file := filepath.Split(path)
+	if strings.Contains(file, "%(program_name)s") {
+		return path // Expanded by supervisord for each program
+	}
+	if renamed, ok := replaceNameToken(file, oldName, newName); ok {
+		return dir + renamed
+	}
+
+	// Keep the clone from writing to the same file
+	ext := filepath.Ext(file)
+	return dir + strings.TrimSuffix(file, ext) + "-" + newName + ext
+}
+
+// replaceNameToken replaces oldName in file where it isn't part of a longer word,
+// so web becomes web-2 in web-error.log but not in webapp.log
+func replaceNameToken(file, oldName, newName string) (string, bool) {
+	isWord := func(b byte) bool {
+		return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+	}
+
+	var sb strings.Builder
+	replaced := false
+	for i := 0; i < len(file); {
+		end := i + len(oldName)
+		if strings.HasPrefix(file[i:], oldName) &&
+			(i == 0 || !isWord(file[i-1])) && (end == len(file) || !isWord(file[end])) {
+			sb.WriteString(newName)
+			i = end
+			replaced = true
+			continue
+		}
+		sb.WriteByte(file[i])
+		i++
+	}
+	return sb.String(), replaced
+}
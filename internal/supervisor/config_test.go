@@ -0,0 +1,57 @@
+package supervisor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigStampChangesWithConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "supervisord.conf")
+	if err := os.WriteFile(path, []byte("[supervisord]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	stamp := ConfigStamp(path)
+	if ConfigStamp(path) != stamp {
+		t.Fatal("stamp changed without changes to the config files")
+	}
+
+	program := filepath.Join(dir, "conf.d", "web.conf")
+	if err := os.WriteFile(program, []byte("[program:web]\ncommand=/usr/bin/web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	added := ConfigStamp(path)
+	if added == stamp {
+		t.Error("stamp didn't change when a program config was added")
+	}
+
+	if err := os.WriteFile(program, []byte("[program:web]\ncommand=/usr/bin/web --port 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ConfigStamp(path) == added {
+		t.Error("stamp didn't change when a program config was edited")
+	}
+}
+
+func TestCloneLogfile(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"/var/log/webapp/web.log", "/var/log/webapp/web-2.log"},
+		{"/var/log/web-error.log", "/var/log/web-2-error.log"},
+		{"/var/log/webapp.log", "/var/log/webapp-web-2.log"},
+		{"/var/log/webapp-web.log", "/var/log/webapp-web-2.log"},
+		{"/var/log/%(program_name)s.log", "/var/log/%(program_name)s.log"},
+		{"AUTO", "AUTO"},
+	}
+	for _, tt := range tests {
+		if got := cloneLogfile(tt.path, "web", "web-2"); got != tt.want {
+			t.Errorf("cloneLogfile(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
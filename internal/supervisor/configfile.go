@@ -0,0 +1,163 @@
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// processSectionKinds are the sections that define processes managed by supervisord
+var processSectionKinds = map[string]bool{
+	"program":       true,
+	"eventlistener": true,
+	"fcgi-program":  true,
+}
+
+// ReadConfigFile returns the content of a config file to be edited as a whole
+func ReadConfigFile(path string) (string, error) {
+	content, err := readFileIfExists(path)
+	if err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// ValidateConfigText checks every section of a config file edited as a whole
+// All problems are reported, each prefixed with its line number
+func ValidateConfigText(content string) error {
+	lines := strings.Split(content, "\n")
+	sections := scanSections(lines)
+	if len(sections) == 0 {
+		return fmt.Errorf("no sections found")
+	}
+
+	var errs []error
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...)))
+	}
+
+	// Options before the first header don't belong to any section
+	for i := 0; i < sections[0].StartLine-1; i++ {
+		if !isBlankOrComment(lines[i]) {
+			fail(i+1, "option outside of a section")
+		}
+	}
+
+	seen := make(map[string]int)
+	for _, s := range sections {
+		header := s.Kind
+		if s.Name != "" {
+			header += ":" + s.Name
+		}
+		if first, ok := seen[header]; ok {
+			fail(s.StartLine, "[%s] is already defined on line %d", header, first)
+		}
+		seen[header] = s.StartLine
+
+		options := make(map[string]string)
+		for i := s.StartLine; i < s.EndLine; i++ {
+			line := lines[i]
+			// Indented lines continue the previous value
+			if isBlankOrComment(line) || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				fail(i+1, "expected key=value in [%s]", header)
+				continue
+			}
+			options[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+
+		if !processSectionKinds[s.Kind] && s.Kind != "group" {
+			continue
+		}
+
+		if s.Name == "" {
+			fail(s.StartLine, "[%s] needs a name, e.g. [%s:myapp]", s.Kind, s.Kind)
+		} else if !programNameRe.MatchString(s.Name) {
+			fail(s.StartLine, "invalid name %q: use letters, digits, '.', '_' and '-'", s.Name)
+		}
+
+		switch s.Kind {
+		case "group":
+			if options["programs"] == "" {
+				fail(s.StartLine, "[%s] needs programs=", header)
+			}
+		case "eventlistener":
+			if options["events"] == "" {
+				fail(s.StartLine, "[%s] needs events=", header)
+			}
+		}
+		if processSectionKinds[s.Kind] && options["command"] == "" {
+			fail(s.StartLine, "[%s] needs command=", header)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SaveConfigText validates content and writes it to path as a whole, recording history
+// original is the content the editor started from: if the file changed on disk
+// in the meantime the save is refused. It returns the names to pass to
+// `supervisorctl update` for the sections affected before and after the change.
+func SaveConfigText(path, original, content string) ([]string, error) {
+	if err := ValidateConfigText(content); err != nil {
+		return nil, err
+	}
+
+	before, err := readFileIfExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if before != original {
+		return nil, fmt.Errorf("%s changed on disk since it was opened, reload and try again", path)
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := writeConfigFile(path, content); err != nil {
+		return nil, err
+	}
+
+	return mergeNames(updateNames(before), updateNames(content)), nil
+}
+
+// updateNames returns the names supervisorctl update knows the sections in content by:
+// groups, event listeners and programs that aren't part of a group
+func updateNames(content string) []string {
+	lines := strings.Split(content, "\n")
+
+	grouped := make(map[string]bool)
+	var names []string
+	for _, s := range scanSections(lines) {
+		if s.Name == "" {
+			continue
+		}
+		if s.Kind == "group" {
+			names = append(names, s.Name)
+			for i := s.StartLine; i < s.EndLine; i++ {
+				key, value, ok := strings.Cut(lines[i], "=")
+				if ok && strings.TrimSpace(key) == "programs" {
+					for _, program := range strings.Split(value, ",") {
+						grouped[strings.TrimSpace(program)] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, s := range scanSections(lines) {
+		if processSectionKinds[s.Kind] && s.Name != "" && !grouped[s.Name] {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// isBlankOrComment returns true for lines without an option or header
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#")
+}
@@ -0,0 +1,375 @@
+package supervisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported formats for import and export
+const (
+	FormatYAML     = "yaml"
+	FormatJSON     = "json"
+	FormatSystemd  = "systemd"  // Export only: one .service unit per program
+	FormatProcfile = "procfile" // Export only: one Procfile entry per program
+)
+
+// ProgramSpec is the structured (YAML/JSON) representation of a program
+// Keys follow supervisord option names so documents read like the INI they replace
+type ProgramSpec struct {
+	Name                  string            `json:"name" yaml:"name"`
+	Command               string            `json:"command" yaml:"command"`
+	Directory             string            `json:"directory,omitempty" yaml:"directory,omitempty"`
+	User                  string            `json:"user,omitempty" yaml:"user,omitempty"`
+	Autostart             *bool             `json:"autostart,omitempty" yaml:"autostart,omitempty"`
+	Autorestart           *bool             `json:"autorestart,omitempty" yaml:"autorestart,omitempty"`
+	StartSecs             int               `json:"startsecs,omitempty" yaml:"startsecs,omitempty"`
+	StartRetries          int               `json:"startretries,omitempty" yaml:"startretries,omitempty"`
+	StdoutLogfile         string            `json:"stdout_logfile,omitempty" yaml:"stdout_logfile,omitempty"`
+	StderrLogfile         string            `json:"stderr_logfile,omitempty" yaml:"stderr_logfile,omitempty"`
+	StdoutLogfileMaxBytes string            `json:"stdout_logfile_maxbytes,omitempty" yaml:"stdout_logfile_maxbytes,omitempty"`
+	StdoutLogfileBackups  int               `json:"stdout_logfile_backups,omitempty" yaml:"stdout_logfile_backups,omitempty"`
+	StderrLogfileMaxBytes string            `json:"stderr_logfile_maxbytes,omitempty" yaml:"stderr_logfile_maxbytes,omitempty"`
+	StderrLogfileBackups  int               `json:"stderr_logfile_backups,omitempty" yaml:"stderr_logfile_backups,omitempty"`
+	Environment           map[string]string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	Priority              int               `json:"priority,omitempty" yaml:"priority,omitempty"`
+	StopSignal            string            `json:"stopsignal,omitempty" yaml:"stopsignal,omitempty"`
+	StopWaitSecs          int               `json:"stopwaitsecs,omitempty" yaml:"stopwaitsecs,omitempty"`
+	Extra                 map[string]string `json:"extra,omitempty" yaml:"extra,omitempty"`
+}
+
+// programDocument is the top-level structure of an import/export file
+type programDocument struct {
+	Programs []*ProgramSpec `json:"programs" yaml:"programs"`
+}
+
+// ImportChange describes what importing a single program will do
+type ImportChange struct {
+	Program  *ProcessConfig
+	Existing *ProcessConfig // nil for new programs
+	Action   string         // "create", "change" or "unchanged"
+	Diff     string
+}
+
+var (
+	programNameRe = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+	optionKeyRe   = regexp.MustCompile(`^[a-z0-9_]+$`)
+	envKeyRe      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// FormatFromPath guesses the format from a file name or extension
+func FormatFromPath(path string) string {
+	if IsProcfile(path) {
+		return FormatProcfile
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".service":
+		return FormatSystemd
+	default:
+		return FormatYAML
+	}
+}
+
+// ExportPrograms encodes programs in the given format
+// For systemd and Procfile exports it also returns notes about settings
+// that have no equivalent in the target format
+func ExportPrograms(progs []*ProcessConfig, format string) ([]byte, []string, error) {
+	switch format {
+	case FormatSystemd:
+		var sb strings.Builder
+		var notes []string
+		for i, prog := range progs {
+			unit, unitNotes := GenerateSystemdUnit(prog)
+			if len(progs) > 1 {
+				if i > 0 {
+					sb.WriteString("\n")
+				}
+				sb.WriteString(fmt.Sprintf("# %s.service\n", prog.Name))
+			}
+			sb.WriteString(unit)
+			notes = append(notes, unitNotes...)
+		}
+		return []byte(sb.String()), notes, nil
+	case FormatProcfile:
+		procfile, notes := GenerateProcfile(progs)
+		return []byte(procfile), notes, nil
+	}
+
+	doc := programDocument{}
+	for _, prog := range progs {
+		doc.Programs = append(doc.Programs, specFromConfig(prog))
+	}
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return append(data, '\n'), nil, nil
+	case FormatYAML, "":
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		return data, nil, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// ExportSystemdUnits writes one <name>.service unit per program into dir
+// It returns notes about settings that have no systemd equivalent
+func ExportSystemdUnits(progs []*ProcessConfig, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var notes []string
+	for _, prog := range progs {
+		unit, unitNotes := GenerateSystemdUnit(prog)
+		path := filepath.Join(dir, prog.Name+".service")
+		if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+			return notes, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		notes = append(notes, unitNotes...)
+	}
+	return notes, nil
+}
+
+// ImportPrograms decodes and validates a YAML or JSON document of programs
+// Unknown keys are rejected, so a misspelled option isn't silently dropped
+func ImportPrograms(data []byte, format string) ([]*ProcessConfig, error) {
+	var doc programDocument
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case FormatYAML, "":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	if len(doc.Programs) == 0 {
+		return nil, fmt.Errorf("no programs found")
+	}
+
+	seen := make(map[string]bool)
+	var progs []*ProcessConfig
+	for i, spec := range doc.Programs {
+		if spec == nil {
+			return nil, fmt.Errorf("program #%d is empty", i+1)
+		}
+		// Sizes are parsed when converting, so they are checked here
+		for key, value := range map[string]string{
+			"stdout_logfile_maxbytes": spec.StdoutLogfileMaxBytes,
+			"stderr_logfile_maxbytes": spec.StderrLogfileMaxBytes,
+		} {
+			if value != "" && !validBytes(value) {
+				return nil, fmt.Errorf("program #%d: invalid %s %q: use a number of bytes, optionally followed by KB, MB or GB", i+1, key, value)
+			}
+		}
+
+		prog := configFromSpec(spec)
+		if err := ValidateProgram(prog); err != nil {
+			return nil, fmt.Errorf("program #%d: %w", i+1, err)
+		}
+		if seen[prog.Name] {
+			return nil, fmt.Errorf("program %s is defined more than once", prog.Name)
+		}
+		seen[prog.Name] = true
+		progs = append(progs, prog)
+	}
+
+	return progs, nil
+}
+
+// ValidateProgram checks that a program config can be written and loaded by supervisord
+func ValidateProgram(prog *ProcessConfig) error {
+	if prog.Name == "" {
+		return fmt.Errorf("program name is required")
+	}
+	if !programNameRe.MatchString(prog.Name) {
+		return fmt.Errorf("invalid program name %q: use letters, digits, '.', '_' and '-'", prog.Name)
+	}
+	if strings.TrimSpace(prog.Command) == "" {
+		return fmt.Errorf("program %s: command is required", prog.Name)
+	}
+
+	// Every value ends up on a single line of the section, a line break would
+	// let it add options or whole sections of its own
+	values := map[string]string{
+		"command":        prog.Command,
+		"directory":      prog.Directory,
+		"user":           prog.User,
+		"stdout_logfile": prog.StdoutLogfile,
+		"stderr_logfile": prog.StderrLogfile,
+		"stopsignal":     prog.StopSignal,
+	}
+	for key, value := range prog.Environment {
+		if !envKeyRe.MatchString(key) {
+			return fmt.Errorf("program %s: invalid environment variable name %q", prog.Name, key)
+		}
+		// environment= is a comma separated list that is read back without unquoting
+		if strings.ContainsAny(value, `,"`) {
+			return fmt.Errorf("program %s: environment variable %s can't contain ',' or '\"'", prog.Name, key)
+		}
+		values["environment "+key] = value
+	}
+	for key, value := range prog.Extra {
+		if !optionKeyRe.MatchString(key) {
+			return fmt.Errorf("program %s: invalid option name %q", prog.Name, key)
+		}
+		values[key] = value
+	}
+	for _, key := range SortedKeys(values) {
+		if strings.ContainsFunc(values[key], isControl) {
+			return fmt.Errorf("program %s: %s contains control characters", prog.Name, key)
+		}
+	}
+	return nil
+}
+
+// isControl returns true for control characters, which includes line breaks
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// PlanImport compares imported programs with the loaded config
+func PlanImport(config *Config, progs []*ProcessConfig) []*ImportChange {
+	var changes []*ImportChange
+	for _, prog := range progs {
+		change := &ImportChange{Program: prog, Action: "create"}
+
+		if existing := config.GetProcessConfig(prog.Name); existing != nil {
+			change.Existing = existing
+			// Write changed programs back to where they are defined
+			prog.SourceFile = existing.SourceFile
+			prog.StartLine = existing.StartLine
+			prog.EndLine = existing.EndLine
+
+			before := programSectionText(existing)
+			after := programSectionText(prog)
+			if before == after {
+				change.Action = "unchanged"
+			} else {
+				change.Action = "change"
+				change.Diff = UnifiedDiff(before, after, prog.Name)
+			}
+		}
+
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// ApplyImport writes created and changed programs
+// It returns the names of the programs that were written
+func ApplyImport(changes []*ImportChange) ([]string, error) {
+	var names []string
+	for _, change := range changes {
+		if change.Action == "unchanged" {
+			continue
+		}
+		if err := SaveProcessConfig(change.Program); err != nil {
+			return names, fmt.Errorf("failed to save %s: %w", change.Program.Name, err)
+		}
+		names = append(names, change.Program.Name)
+	}
+	return names, nil
+}
+
+// specFromConfig converts a ProcessConfig to its structured representation
+func specFromConfig(prog *ProcessConfig) *ProgramSpec {
+	autostart := prog.Autostart
+	spec := &ProgramSpec{
+		Name:                 prog.Name,
+		Command:              prog.Command,
+		Directory:            prog.Directory,
+		User:                 prog.User,
+		Autostart:            &autostart,
+		StartSecs:            prog.StartSecs,
+		StartRetries:         prog.StartRetries,
+		StdoutLogfile:        prog.StdoutLogfile,
+		StderrLogfile:        prog.StderrLogfile,
+		StdoutLogfileBackups: prog.StdoutLogfileBackups,
+		StderrLogfileBackups: prog.StderrLogfileBackups,
+		Priority:             prog.Priority,
+		StopSignal:           prog.StopSignal,
+		StopWaitSecs:         prog.StopWaitSecs,
+	}
+	// autorestart=unexpected is supervisord's default, so it is left out
+	if !prog.RestartUnexpected {
+		autorestart := prog.Autorestart
+		spec.Autorestart = &autorestart
+	}
+	if prog.StdoutLogfileMaxBytes > 0 {
+		spec.StdoutLogfileMaxBytes = formatBytes(prog.StdoutLogfileMaxBytes)
+	}
+	if prog.StderrLogfileMaxBytes > 0 {
+		spec.StderrLogfileMaxBytes = formatBytes(prog.StderrLogfileMaxBytes)
+	}
+	if len(prog.Environment) > 0 {
+		spec.Environment = prog.Environment
+	}
+	if len(prog.Extra) > 0 {
+		spec.Extra = prog.Extra
+	}
+	return spec
+}
+
+// configFromSpec converts a structured program to a ProcessConfig
+func configFromSpec(spec *ProgramSpec) *ProcessConfig {
+	prog := &ProcessConfig{
+		Name:                  strings.TrimSpace(spec.Name),
+		Command:               spec.Command,
+		Directory:             spec.Directory,
+		User:                  spec.User,
+		Autostart:             true, // supervisord defaults
+		RestartUnexpected:     true,
+		StartSecs:             spec.StartSecs,
+		StartRetries:          spec.StartRetries,
+		StdoutLogfile:         spec.StdoutLogfile,
+		StderrLogfile:         spec.StderrLogfile,
+		StdoutLogfileMaxBytes: parseBytes(spec.StdoutLogfileMaxBytes),
+		StdoutLogfileBackups:  spec.StdoutLogfileBackups,
+		StderrLogfileMaxBytes: parseBytes(spec.StderrLogfileMaxBytes),
+		StderrLogfileBackups:  spec.StderrLogfileBackups,
+		Environment:           make(map[string]string),
+		Extra:                 make(map[string]string),
+		Priority:              spec.Priority,
+		StopSignal:            spec.StopSignal,
+		StopWaitSecs:          spec.StopWaitSecs,
+	}
+	if spec.Autostart != nil {
+		prog.Autostart = *spec.Autostart
+	}
+	if spec.Autorestart != nil {
+		prog.Autorestart = *spec.Autorestart
+		prog.RestartUnexpected = false
+	}
+	for key, value := range spec.Environment {
+		prog.Environment[key] = value
+	}
+	for key, value := range spec.Extra {
+		prog.Extra[key] = value
+	}
+	return prog
+}
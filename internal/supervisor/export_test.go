@@ -0,0 +1,90 @@
+package supervisor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestConfig writes content to a config file and loads the programs in it
+func loadTestConfig(t *testing.T, content string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "supervisord.conf")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{Path: path, Supervisord: make(map[string]string)}
+	if err := loadConfigFile(path, config); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestExportKeepsDefaultAutorestart(t *testing.T) {
+	config := loadTestConfig(t, "[program:web]\ncommand=/usr/bin/web\n")
+	prog := config.GetProcessConfig("web")
+	if prog.AutorestartValue() != "unexpected" {
+		t.Fatalf("loaded autorestart = %s, want unexpected", prog.AutorestartValue())
+	}
+
+	data, _, err := ExportPrograms([]*ProcessConfig{prog}, FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "autorestart") {
+		t.Errorf("export should leave out the default autorestart:\n%s", data)
+	}
+
+	progs, err := ImportPrograms(data, FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := progs[0].AutorestartValue(); got != "unexpected" {
+		t.Errorf("imported autorestart = %s, want unexpected", got)
+	}
+
+	unit, _ := GenerateSystemdUnit(prog)
+	if !strings.Contains(unit, "Restart=on-failure\n") {
+		t.Errorf("systemd unit should restart on failure:\n%s", unit)
+	}
+}
+
+func TestImportRejectsInvalidPrograms(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   string
+	}{
+		{"unparsable size", FormatYAML, "programs:\n  - name: web\n    command: web\n    stdout_logfile_maxbytes: 50 MB\n", "stdout_logfile_maxbytes"},
+		{"size without number", FormatJSON, `{"programs": [{"name": "web", "command": "web", "stderr_logfile_maxbytes": "lots"}]}`, "stderr_logfile_maxbytes"},
+		{"misspelled YAML key", FormatYAML, "programs:\n  - name: web\n    command: web\n    autorestrat: true\n", "autorestrat"},
+		{"misspelled JSON key", FormatJSON, `{"programs": [{"name": "web", "command": "web", "directroy": "/srv"}]}`, "directroy"},
+		{"comma in environment", FormatYAML, "programs:\n  - name: web\n    command: web\n    environment:\n      HOSTS: a,b\n", "HOSTS"},
+		{"quote in environment", FormatYAML, "programs:\n  - name: web\n    command: web\n    environment:\n      GREETING: 'say \"hi\"'\n", "GREETING"},
+		{"line break in command", FormatYAML, "programs:\n  - name: web\n    command: \"web\\n[program:evil]\"\n", "control characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportPrograms([]byte(tt.data), tt.format)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q doesn't mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportAcceptsValidSizes(t *testing.T) {
+	data := "programs:\n  - name: web\n    command: web\n    stdout_logfile_maxbytes: 50MB\n    stderr_logfile_maxbytes: \"0\"\n"
+	progs, err := ImportPrograms([]byte(data), FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := progs[0].StdoutLogfileMaxBytes; got != 50*1024*1024 {
+		t.Errorf("stdout_logfile_maxbytes = %d, want 50MB", got)
+	}
+}
@@ -0,0 +1,86 @@
+package supervisor
+
+import (
+	"fmt"
+	"os"
+)
+
+// readFileIfExists returns the file content, or an empty string if it doesn't exist
+func readFileIfExists(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	return string(data), nil
+}
+
+// writeFile writes content to a config file
+// Permission errors are retried through sudo once it has been enabled
+func writeFile(path, content string) error {
+	err := os.WriteFile(path, []byte(content), 0644)
+	if IsPermissionError(err) && SudoEnabled() {
+		return sudoWriteFile(path, content)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// makeConfigDir creates a config directory and its parents
+func makeConfigDir(dir string) error {
+	err := os.MkdirAll(dir, 0755)
+	if IsPermissionError(err) && SudoEnabled() {
+		_, err = runSudo("mkdir", "-p", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return nil
+}
+
+// writeConfigFile writes a config file and records the change in history
+func writeConfigFile(path, content string) error {
+	before, err := readFileIfExists(path)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFile(path, content); err != nil {
+		return err
+	}
+
+	// A failure to record history shouldn't fail the write, it is reported separately
+	noteHistoryError(recordHistory(HistoryWrite, path, before, content))
+	return nil
+}
+
+// removeConfigFile removes a config file
+func removeConfigFile(path string) error {
+	err := os.Remove(path)
+	if IsPermissionError(err) && SudoEnabled() {
+		_, err = runSudo("rm", "-f", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete config file: %w", err)
+	}
+	return nil
+}
+
+// deleteConfigFile removes a config file and records the deletion in history
+func deleteConfigFile(path string) error {
+	before, err := readFileIfExists(path)
+	if err != nil {
+		return err
+	}
+
+	if err := removeConfigFile(path); err != nil {
+		return err
+	}
+
+	noteHistoryError(recordHistory(HistoryDelete, path, before, ""))
+	return nil
+}
@@ -0,0 +1,205 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// History actions
+const (
+	HistoryWrite   = "write"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+)
+
+// HistoryEntry is a single recorded change to a config file written by god
+type HistoryEntry struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Action   string    `json:"action"`
+	Path     string    `json:"path"`
+	Programs []string  `json:"programs"`
+	Before   string    `json:"before"`
+	After    string    `json:"after"`
+	Diff     string    `json:"diff"`
+}
+
+// Content returns the version of the file this entry can be restored to
+// For deletions that is the content the file had before it was removed
+func (e *HistoryEntry) Content() string {
+	if e.Action == HistoryDelete {
+		return e.Before
+	}
+	return e.After
+}
+
+// HasProgram returns true if the entry touched the given program
+func (e *HistoryEntry) HasProgram(name string) bool {
+	for _, prog := range e.Programs {
+		if prog == name {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	historyMu  sync.Mutex
+	historyErr error // Last failure to record history, not reported yet
+)
+
+// noteHistoryError remembers a failure to record history so it can be reported
+// The change itself has been written already, so it isn't failed because of it
+func noteHistoryError(err error) {
+	if err == nil {
+		return
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	historyErr = err
+}
+
+// TakeHistoryError returns the last failure to record history since the previous call
+func TakeHistoryError() error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	err := historyErr
+	historyErr = nil
+	return err
+}
+
+// HistoryDir returns the directory where config history is stored
+func HistoryDir() string {
+	return filepath.Join(StateDir(), "history")
+}
+
+// recordHistory stores a new history entry for a config file change
+func recordHistory(action, path, before, after string) error {
+	if before == after {
+		return nil
+	}
+
+	dir := HistoryDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	now := time.Now()
+	entry := &HistoryEntry{
+		ID:       strconv.FormatInt(now.UnixNano(), 10),
+		Time:     now,
+		User:     CurrentUser(),
+		Action:   action,
+		Path:     path,
+		Programs: mergeNames(programNames(before), programNames(after)),
+		Before:   before,
+		After:    after,
+		Diff:     UnifiedDiff(before, after, path),
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, entry.ID+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory returns all recorded history entries, newest first
+// If program is not empty, only entries touching that program are returned
+func LoadHistory(program string) ([]*HistoryEntry, error) {
+	files, err := filepath.Glob(filepath.Join(HistoryDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*HistoryEntry
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			// Skip corrupted entries
+			continue
+		}
+		if program != "" && !entry.HasProgram(program) {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	return entries, nil
+}
+
+// RestoreHistoryEntry puts the file back to the version recorded in entry
+// It returns the names to pass to supervisorctl update for the affected sections
+func RestoreHistoryEntry(entry *HistoryEntry) ([]string, error) {
+	before, err := readFileIfExists(entry.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	content := entry.Content()
+	if content == "" {
+		if before != "" {
+			if err := removeConfigFile(entry.Path); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if err := makeConfigDir(filepath.Dir(entry.Path)); err != nil {
+			return nil, err
+		}
+		if err := writeFile(entry.Path, content); err != nil {
+			return nil, err
+		}
+	}
+
+	// Restores are recorded too, so they can be undone
+	noteHistoryError(recordHistory(HistoryRestore, entry.Path, before, content))
+
+	return mergeNames(updateNames(before), updateNames(content)), nil
+}
+
+// programNames returns the names of all [program:x] sections in content
+func programNames(content string) []string {
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[program:") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSuffix(strings.TrimPrefix(line, "[program:"), "]")
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// mergeNames merges two name lists, dropping duplicates
+func mergeNames(a, b []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
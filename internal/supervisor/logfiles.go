@@ -0,0 +1,216 @@
+package supervisor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LogFiles are the files supervisord writes the output of a process to
+type LogFiles struct {
+	Stdout         string // Empty if stdout isn't logged to a file
+	Stderr         string // Empty if stderr isn't logged to a file of its own
+	RedirectStderr bool   // stderr is written to the stdout log (redirect_stderr=true)
+	StdoutNote     string // Why there is no stdout file
+	StderrNote     string // Why there is no stderr file
+}
+
+// logPathVarRe matches the expansions allowed in logfile paths, like %(program_name)s or %(process_num)02d
+var logPathVarRe = regexp.MustCompile(`%\(([\w.]+)\)([-#0 +]*\d*)([sd])`)
+
+// processNumRe matches the process number at the end of a process name, as in worker_02
+var processNumRe = regexp.MustCompile(`\d+$`)
+
+// ResolveLogFiles sets where each process writes its output
+// reported are the files supervisord itself reports (see Client.ReportedLogFiles),
+// which may be nil. Otherwise AUTO logfiles are looked up in the childlogdir of config.
+func ResolveLogFiles(processes []*Process, config *Config, reported map[string]LogFiles) {
+	for _, proc := range processes {
+		proc.Logs = resolveLogFiles(proc, config, reported[proc.Name])
+	}
+}
+
+// resolveLogFiles resolves the log files of a single process
+func resolveLogFiles(proc *Process, config *Config, reported LogFiles) LogFiles {
+	cfg := proc.Config
+	if cfg == nil {
+		return reported
+	}
+
+	logs := LogFiles{RedirectStderr: strings.EqualFold(cfg.Extra["redirect_stderr"], "true")}
+	logs.Stdout, logs.StdoutNote = resolveLogPath(cfg.StdoutLogfile, reported.Stdout, "stdout", proc, config)
+	if logs.RedirectStderr {
+		logs.StderrNote = "stderr is redirected to the stdout log"
+	} else {
+		logs.Stderr, logs.StderrNote = resolveLogPath(cfg.StderrLogfile, reported.Stderr, "stderr", proc, config)
+	}
+	return logs
+}
+
+// resolveLogPath resolves the stdout_logfile or stderr_logfile value of a process
+// It returns the path, or an empty path and the reason there is none.
+func resolveLogPath(value, reported, channel string, proc *Process, config *Config) (string, string) {
+	switch strings.ToUpper(value) {
+	case "NONE":
+		return "", fmt.Sprintf("%s is not logged (%s_logfile=NONE)", channel, channel)
+	case "SYSLOG":
+		return "", fmt.Sprintf("%s is sent to syslog", channel)
+	}
+	if reported != "" {
+		return reported, ""
+	}
+
+	// AUTO is the default: supervisord picks a file name in childlogdir
+	if value == "" || strings.EqualFold(value, "AUTO") {
+		dir := childLogDir(config)
+		if path := findAutoLog(dir, processName(proc.Name), channel, config); path != "" {
+			return path, ""
+		}
+		return "", fmt.Sprintf("no AUTO %s logfile in %s yet", channel, dir)
+	}
+	return expandLogPath(value, proc, config), ""
+}
+
+// childLogDir returns the directory supervisord writes AUTO logfiles to
+func childLogDir(config *Config) string {
+	if config != nil {
+		if dir := config.Supervisord["childlogdir"]; dir != "" {
+			return expandHome(expandHere(dir, config.Path))
+		}
+	}
+	// Python's tempfile.gettempdir()
+	return os.TempDir()
+}
+
+// findAutoLog returns the newest AUTO logfile of a process, like
+// worker-stdout---supervisor-a1b2c3.log, or an empty string if there is none
+func findAutoLog(dir, name, channel string, config *Config) string {
+	identifier := "supervisor"
+	if config != nil && config.Supervisord["identifier"] != "" {
+		identifier = config.Supervisord["identifier"]
+	}
+
+	pattern := filepath.Join(dir, fmt.Sprintf("%s-%s---%s-*.log", name, channel, identifier))
+	matches, _ := filepath.Glob(pattern)
+	newest := ""
+	var newestTime int64
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if t := info.ModTime().UnixNano(); newest == "" || t > newestTime {
+			newest, newestTime = match, t
+		}
+	}
+	return newest
+}
+
+// processName returns the name of a process without its group
+func processName(name string) string {
+	if _, process, ok := strings.Cut(name, ":"); ok {
+		return process
+	}
+	return name
+}
+
+// expandLogPath expands %(name)s expressions and ~ in a logfile path
+func expandLogPath(path string, proc *Process, config *Config) string {
+	name := processName(proc.Name)
+	group := proc.Config.Name
+	if g, _, ok := strings.Cut(proc.Name, ":"); ok {
+		group = g
+	}
+
+	num := 0
+	if match := processNumRe.FindString(name); match != "" {
+		num, _ = strconv.Atoi(match)
+	}
+
+	here := ""
+	if proc.Config.SourceFile != "" {
+		here = filepath.Dir(proc.Config.SourceFile)
+	} else if config != nil {
+		here = filepath.Dir(config.Path)
+	}
+
+	path = logPathVarRe.ReplaceAllStringFunc(path, func(expr string) string {
+		parts := logPathVarRe.FindStringSubmatch(expr)
+		var value interface{}
+		switch key := parts[1]; {
+		case key == "program_name":
+			value = proc.Config.Name
+		case key == "process_name":
+			value = name
+		case key == "group_name":
+			value = group
+		case key == "process_num":
+			value = num
+		case key == "here":
+			value = here
+		case key == "host_node_name":
+			value, _ = os.Hostname()
+		case strings.HasPrefix(key, "ENV_"):
+			value = os.Getenv(strings.TrimPrefix(key, "ENV_"))
+		default:
+			return expr
+		}
+		if parts[3] == "d" {
+			if _, ok := value.(int); !ok {
+				return expr
+			}
+		}
+		return fmt.Sprintf("%"+parts[2]+parts[3], value)
+	})
+	return expandHome(strings.ReplaceAll(path, "%%", "%"))
+}
+
+// expandHere expands %(here)s, the directory of the config file
+func expandHere(path, configPath string) string {
+	return strings.ReplaceAll(path, "%(here)s", filepath.Dir(configPath))
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// DaemonLogFile returns the main log of supervisord itself, from the logfile
+// setting of the [supervisord] section. It returns an empty path and the reason
+// if the log can't be found.
+func DaemonLogFile(config *Config) (string, string) {
+	value := ""
+	if config != nil {
+		value = config.Supervisord["logfile"]
+	}
+	switch strings.ToUpper(value) {
+	case "NONE":
+		return "", "supervisord doesn't write a log (logfile=NONE)"
+	case "SYSLOG":
+		return "", "supervisord logs to syslog"
+	}
+	if value != "" {
+		return expandHome(expandHere(value, config.Path)), ""
+	}
+
+	// The default is supervisord.log in the directory supervisord was started from
+	var candidates []string
+	if config != nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(config.Path), "supervisord.log"))
+	}
+	candidates = append(candidates, "/var/log/supervisor/supervisord.log", "/var/log/supervisord.log")
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, ""
+		}
+	}
+	return "", "logfile isn't set in [supervisord], so supervisord writes supervisord.log in the directory it was started from"
+}
@@ -0,0 +1,46 @@
+package supervisor
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// StateDir returns the directory where god keeps its own state (history, etc.)
+// It honors $XDG_STATE_HOME and falls back to ~/.local/state/god
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "god")
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".local", "state", "god")
+	}
+	return filepath.Join(os.TempDir(), "god")
+}
+
+// ConfigDir returns the directory holding god's own configuration
+// It honors $XDG_CONFIG_HOME and falls back to ~/.config/god
+func ConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "god")
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".config", "god")
+	}
+	return filepath.Join(os.TempDir(), "god")
+}
+
+// CurrentUser returns the name of the OS user running god
+// When running under sudo, the invoking user is reported instead of root
+func CurrentUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
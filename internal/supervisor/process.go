@@ -1,6 +1,7 @@
 package supervisor
 
 import (
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,7 @@ type Process struct {
 	PID    int
 	Uptime time.Duration
 	Config *ProcessConfig
+	Logs   LogFiles // Where the output is written, see ResolveLogFiles
 }
 
 // ProcessConfig represents the configuration for a supervisord process
@@ -21,6 +23,7 @@ type ProcessConfig struct {
 	User                  string
 	Autostart             bool
 	Autorestart           bool
+	RestartUnexpected     bool // autorestart=unexpected (supervisord's default), Autorestart is false then
 	StartSecs             int
 	StartRetries          int
 	StdoutLogfile         string
@@ -33,6 +36,29 @@ type ProcessConfig struct {
 	Priority              int
 	StopSignal            string
 	StopWaitSecs          int
+	Extra                 map[string]string // Options not modeled above, kept as-is
+
+	SourceFile string // File the program is defined in (empty for new programs)
+	StartLine  int    // Line of the [program:name] header in SourceFile (1-based)
+	EndLine    int    // Last line belonging to the section in SourceFile
+}
+
+// AutorestartValue returns the value of the autorestart option
+func (c *ProcessConfig) AutorestartValue() string {
+	if c.Autorestart {
+		return "true"
+	}
+	if c.RestartUnexpected {
+		return "unexpected"
+	}
+	return "false"
+}
+
+// SetAutorestart sets the autorestart option from its config value
+func (c *ProcessConfig) SetAutorestart(value string) {
+	value = strings.ToLower(value)
+	c.Autorestart = value == "true"
+	c.RestartUnexpected = value == "unexpected"
 }
 
 // IsRunning returns true if the process is currently running
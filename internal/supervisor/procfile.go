@@ -0,0 +1,148 @@
+package supervisor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	procfileLineRe = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.+)$`)
+	shellSyntaxRe  = regexp.MustCompile("[$&|;<>`*]")
+	invalidNameRe  = regexp.MustCompile(`[^A-Za-z0-9_.\-]+`)
+)
+
+// IsProcfile returns true if path looks like a Procfile
+func IsProcfile(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), "Procfile")
+}
+
+// ParseProcfile converts a Procfile into program configs, one per process type
+// dir is the directory the Procfile lives in: it becomes each program's directory
+// and its name prefixes program names. Lines that can't be converted are reported as warnings.
+func ParseProcfile(data []byte, dir string) ([]*ProcessConfig, []string) {
+	var progs []*ProcessConfig
+	var warnings []string
+
+	prefix := programName(filepath.Base(dir))
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		matches := procfileLineRe.FindStringSubmatch(line)
+		if matches == nil {
+			warnings = append(warnings, fmt.Sprintf("line %d: not a 'name: command' entry, skipped", lineNum))
+			continue
+		}
+
+		name := matches[1]
+		if prefix != "" {
+			name = prefix + "-" + name
+		}
+
+		command, wrapped := shellCommand(strings.TrimSpace(matches[2]))
+		if wrapped {
+			warnings = append(warnings, fmt.Sprintf("%s: command uses shell syntax, wrapped in /bin/sh -c", name))
+		}
+		if strings.Contains(matches[2], "$PORT") {
+			warnings = append(warnings, fmt.Sprintf("%s: command uses $PORT, set it in environment", name))
+		}
+
+		progs = append(progs, &ProcessConfig{
+			Name:        name,
+			Command:     command,
+			Directory:   dir,
+			Autostart:   true,
+			Autorestart: true,
+			Environment: make(map[string]string),
+			Extra:       make(map[string]string),
+		})
+	}
+
+	if len(progs) == 0 {
+		warnings = append(warnings, "no processes found")
+	}
+
+	return progs, warnings
+}
+
+// shellCommand wraps a command in /bin/sh -c if it relies on shell syntax,
+// since supervisord executes commands directly
+func shellCommand(command string) (string, bool) {
+	if !shellSyntaxRe.MatchString(command) {
+		return command, false
+	}
+	return "/bin/sh -c " + shellQuote(command), true
+}
+
+// programName turns an arbitrary string into a valid program name
+func programName(s string) string {
+	s = invalidNameRe.ReplaceAllString(s, "-")
+	return strings.Trim(s, "-.")
+}
+
+// GenerateProcfile converts programs into Procfile entries
+// Settings without a Procfile equivalent are returned as notes prefixed with the program name.
+func GenerateProcfile(progs []*ProcessConfig) (string, []string) {
+	var sb strings.Builder
+	var notes []string
+
+	for _, prog := range progs {
+		note := func(format string, args ...interface{}) {
+			notes = append(notes, prog.Name+": "+fmt.Sprintf(format, args...))
+		}
+
+		// Procfile process types only allow letters, digits, '_' and '-'
+		name := strings.ReplaceAll(programName(prog.Name), ".", "_")
+
+		command := prog.Command
+		env := SortedKeys(prog.Environment)
+		for i := len(env) - 1; i >= 0; i-- {
+			command = fmt.Sprintf("%s=%s %s", env[i], shellQuote(prog.Environment[env[i]]), command)
+		}
+		if prog.Directory != "" {
+			command = fmt.Sprintf("cd %s && %s", shellQuote(prog.Directory), command)
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s\n", name, command))
+
+		if prog.User != "" {
+			note("user=%s has no equivalent, processes run as the invoking user", prog.User)
+		}
+		if prog.StdoutLogfile != "" || prog.StderrLogfile != "" {
+			note("log files have no equivalent, output goes to the process manager")
+		}
+		if prog.StopSignal != "" || prog.StopWaitSecs > 0 {
+			note("stopsignal/stopwaitsecs have no equivalent")
+		}
+		if !prog.Autostart || !prog.Autorestart {
+			note("autostart/autorestart are handled by the process manager")
+		}
+		if prog.Priority > 0 || prog.StartSecs > 0 || prog.StartRetries > 0 {
+			note("priority, startsecs and startretries have no equivalent")
+		}
+		for _, key := range SortedKeys(prog.Extra) {
+			note("%s=%s has no equivalent", key, prog.Extra[key])
+		}
+	}
+
+	return sb.String(), notes
+}
+
+// shellQuote quotes a value for use in a shell command if needed
+func shellQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t'\"$&|;<>`*\\") {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
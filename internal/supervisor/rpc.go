@@ -0,0 +1,126 @@
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// rpcTimeout limits calls to supervisord's XML-RPC interface
+const rpcTimeout = 2 * time.Second
+
+// rpcValue is an XML-RPC value; only the types supervisord returns are decoded
+type rpcValue struct {
+	String  *string     `xml:"string"`
+	Int     *int        `xml:"int"`
+	Boolean *int        `xml:"boolean"`
+	Members []rpcMember `xml:"struct>member"`
+	Array   []rpcValue  `xml:"array>data>value"`
+	Text    string      `xml:",chardata"` // Values without a type are strings
+}
+
+// rpcMember is a member of an XML-RPC struct
+type rpcMember struct {
+	Name  string   `xml:"name"`
+	Value rpcValue `xml:"value"`
+}
+
+// rpcResponse is an XML-RPC method response or fault
+type rpcResponse struct {
+	Params []rpcValue `xml:"params>param>value"`
+	Fault  *rpcValue  `xml:"fault>value"`
+}
+
+// str returns a string value
+func (v rpcValue) str() string {
+	if v.String != nil {
+		return *v.String
+	}
+	return strings.TrimSpace(v.Text)
+}
+
+// member returns the string value of a struct member
+func (v rpcValue) member(name string) string {
+	for _, m := range v.Members {
+		if m.Name == name {
+			return m.Value.str()
+		}
+	}
+	return ""
+}
+
+// ReportedLogFiles asks supervisord where it writes the output of each process
+// This resolves AUTO logfiles to the files supervisord generated. It only works
+// when the unix socket of supervisord (see SocketPath) is readable; the error says
+// why otherwise.
+func (c *Client) ReportedLogFiles(serverURL string) (map[string]LogFiles, error) {
+	result, err := callRPC(serverURL, "supervisor.getAllProcessInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]LogFiles)
+	for _, info := range result.Array {
+		name := info.member("name")
+		if group := info.member("group"); group != "" && group != name {
+			name = group + ":" + name
+		}
+		files[name] = LogFiles{
+			Stdout: info.member("stdout_logfile"),
+			Stderr: info.member("stderr_logfile"),
+		}
+	}
+	return files, nil
+}
+
+// callRPC calls an XML-RPC method without parameters on supervisord's unix socket
+func callRPC(serverURL, method string) (*rpcValue, error) {
+	socket, ok := strings.CutPrefix(serverURL, "unix://")
+	if !ok {
+		return nil, fmt.Errorf("only unix sockets are supported, not %s", serverURL)
+	}
+
+	client := &http.Client{
+		Timeout: rpcTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+
+	body := fmt.Sprintf(`<?xml version="1.0"?><methodCall><methodName>%s</methodName><params></params></methodCall>`, method)
+	// The host is ignored, the request goes to the socket
+	resp, err := client.Post("http://supervisord/RPC2", "text/xml", bytes.NewBufferString(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to call %s: %s", method, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+
+	var response rpcResponse
+	if err := xml.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	if response.Fault != nil {
+		return nil, fmt.Errorf("%s failed: %s", method, response.Fault.member("faultString"))
+	}
+	if len(response.Params) == 0 {
+		return nil, fmt.Errorf("%s returned nothing", method)
+	}
+	return &response.Params[0], nil
+}
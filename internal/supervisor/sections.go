@@ -0,0 +1,62 @@
+package supervisor
+
+import (
+	"strings"
+)
+
+// section describes where an INI section lives in a config file
+type section struct {
+	Kind      string // Part of the header before ':' (e.g. "program", "group", "supervisord")
+	Name      string // Part of the header after ':' (empty for sections like [supervisord])
+	StartLine int    // Line of the header (1-based)
+	EndLine   int    // Last non-blank, non-comment line of the section
+}
+
+// scanSections returns all sections found in lines
+func scanSections(lines []string) []section {
+	var sections []section
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Skip empty lines and comments, they don't extend a section
+		if trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			kind, name, _ := strings.Cut(strings.Trim(trimmed, "[]"), ":")
+			sections = append(sections, section{
+				Kind:      kind,
+				Name:      name,
+				StartLine: i + 1,
+				EndLine:   i + 1,
+			})
+			continue
+		}
+
+		if len(sections) > 0 {
+			sections[len(sections)-1].EndLine = i + 1
+		}
+	}
+	return sections
+}
+
+// sectionAt returns the section whose header is on the given line
+func sectionAt(lines []string, startLine int) (section, bool) {
+	for _, s := range scanSections(lines) {
+		if s.StartLine == startLine {
+			return s, true
+		}
+	}
+	return section{}, false
+}
+
+// findSection returns the first section with the given kind and name
+func findSection(lines []string, kind, name string) (section, bool) {
+	for _, s := range scanSections(lines) {
+		if s.Kind == kind && s.Name == name {
+			return s, true
+		}
+	}
+	return section{}, false
+}
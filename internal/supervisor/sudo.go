@@ -0,0 +1,141 @@
+package supervisor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ElevatedOperation is an operation that was performed through sudo
+type ElevatedOperation struct {
+	Time    time.Time
+	Command string
+	Err     error
+}
+
+// elevatedHistory is the number of elevated operations kept for the log
+const elevatedHistory = 200
+
+// sudoState holds the password used for elevated operations
+// It is kept in memory only, for the lifetime of the process
+var sudoState struct {
+	mu       sync.Mutex
+	password string
+	enabled  bool
+	log      []ElevatedOperation
+}
+
+// IsPermissionError returns true if err was caused by missing permissions,
+// meaning the operation can be retried with sudo
+func IsPermissionError(err error) bool {
+	return err != nil && errors.Is(err, fs.ErrPermission)
+}
+
+// SudoEnabled returns true once a sudo password has been accepted
+func SudoEnabled() bool {
+	sudoState.mu.Lock()
+	defer sudoState.mu.Unlock()
+	return sudoState.enabled
+}
+
+// EnableSudo validates the password with sudo and uses it for later operations
+// that fail with a permission error
+func EnableSudo(password string) error {
+	if _, err := exec.LookPath("sudo"); err != nil {
+		return fmt.Errorf("sudo is not available: %w", err)
+	}
+
+	cmd := exec.Command("sudo", "-S", "-k", "-p", "", "-v")
+	cmd.Stdin = strings.NewReader(password + "\n")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		msg := strings.TrimSpace(string(output))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("sudo authentication failed: %s", msg)
+	}
+
+	sudoState.mu.Lock()
+	sudoState.password = password
+	sudoState.enabled = true
+	sudoState.mu.Unlock()
+	return nil
+}
+
+// ElevatedOperations returns the latest operations performed through sudo, oldest first
+func ElevatedOperations() []ElevatedOperation {
+	sudoState.mu.Lock()
+	defer sudoState.mu.Unlock()
+	return append([]ElevatedOperation{}, sudoState.log...)
+}
+
+// runSudo runs a command through sudo with the stored password
+func runSudo(name string, args ...string) ([]byte, error) {
+	sudoState.mu.Lock()
+	password := sudoState.password
+	sudoState.mu.Unlock()
+
+	cmd := exec.Command("sudo", append([]string{"-S", "-p", "", "--", name}, args...)...)
+	cmd.Stdin = strings.NewReader(password + "\n")
+	output, err := cmd.CombinedOutput()
+
+	op := ElevatedOperation{
+		Time:    time.Now(),
+		Command: strings.Join(append([]string{name}, args...), " "),
+	}
+	if err != nil {
+		op.Err = fmt.Errorf("%s", strings.TrimSpace(string(output)))
+		if op.Err.Error() == "" {
+			op.Err = err
+		}
+	}
+
+	sudoState.mu.Lock()
+	sudoState.log = append(sudoState.log, op)
+	if len(sudoState.log) > elevatedHistory {
+		sudoState.log = append([]ElevatedOperation{}, sudoState.log[len(sudoState.log)-elevatedHistory:]...)
+	}
+	sudoState.mu.Unlock()
+
+	return output, op.Err
+}
+
+// sudoWriteFile writes content to path as root, through a temporary file
+// Existing files keep their owner and mode
+func sudoWriteFile(path, content string) error {
+	tmp, err := os.CreateTemp("", "god-*.conf")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// cp into an existing file preserves its ownership and mode
+	if _, err := runSudo("cp", tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config file with sudo: %w", err)
+	}
+	if _, err := runSudo("chmod", "a+r", path); err != nil {
+		return fmt.Errorf("failed to write config file with sudo: %w", err)
+	}
+	return nil
+}
+
+// supervisorctlPermissionDenied returns true if supervisorctl failed because
+// the socket or config is only accessible to root
+func supervisorctlPermissionDenied(output []byte) bool {
+	return bytes.Contains(output, []byte("Permission denied")) ||
+		bytes.Contains(output, []byte("PermissionError"))
+}
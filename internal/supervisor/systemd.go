@@ -0,0 +1,413 @@
+package supervisor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unitDirective is a single key=value line of a systemd unit
+type unitDirective struct {
+	section string
+	key     string
+	value   string
+}
+
+// IsSystemdUnit returns true if path looks like a systemd service unit
+func IsSystemdUnit(path string) bool {
+	return strings.HasSuffix(path, ".service")
+}
+
+// ParseSystemdUnit converts a systemd service unit into a program config
+// name is used as the program name, usually the unit file name without ".service".
+// Directives without a supervisord equivalent are reported as warnings.
+func ParseSystemdUnit(data []byte, name string) (*ProcessConfig, []string, error) {
+	directives, err := parseUnitFile(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prog := &ProcessConfig{
+		Name:        programName(name),
+		Autostart:   false,
+		Autorestart: false,
+		Environment: make(map[string]string),
+		Extra:       make(map[string]string),
+	}
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	for _, d := range directives {
+		switch d.section {
+		case "Unit":
+			if d.key != "Description" && d.key != "Documentation" {
+				warn("[Unit] %s=%s is not supported", d.key, d.value)
+			}
+			continue
+		case "Install":
+			// Being wanted by a target means the service starts on boot
+			if d.key == "WantedBy" || d.key == "RequiredBy" {
+				prog.Autostart = true
+			} else {
+				warn("[Install] %s=%s is not supported", d.key, d.value)
+			}
+			continue
+		case "Service":
+		default:
+			warn("[%s] %s=%s is not supported", d.section, d.key, d.value)
+			continue
+		}
+
+		if strings.Contains(strings.ReplaceAll(d.value, "%%", ""), "%") {
+			warn("%s uses unit specifiers (%%), check the converted value", d.key)
+		}
+		d.value = strings.ReplaceAll(d.value, "%%", "%")
+
+		switch d.key {
+		case "ExecStart":
+			if prog.Command != "" {
+				warn("multiple ExecStart lines, only the first one is used")
+				continue
+			}
+			command := strings.TrimLeft(d.value, "-@+!:")
+			if command != d.value {
+				warn("ExecStart prefixes (-, @, +, !, :) are not supported and were dropped")
+			}
+			prog.Command = command
+		case "WorkingDirectory":
+			prog.Directory = strings.TrimPrefix(d.value, "-")
+		case "User":
+			prog.User = d.value
+		case "Environment":
+			for _, word := range splitUnitWords(d.value) {
+				if key, value, ok := strings.Cut(word, "="); ok {
+					prog.Environment[key] = value
+				}
+			}
+		case "EnvironmentFile":
+			path := d.value
+			optional := strings.HasPrefix(path, "-")
+			path = strings.TrimPrefix(path, "-")
+			env, err := readEnvironmentFile(path)
+			if err != nil {
+				if !optional {
+					warn("EnvironmentFile %s could not be read: %v", path, err)
+				}
+				continue
+			}
+			for key, value := range env {
+				prog.Environment[key] = value
+			}
+		case "Restart":
+			switch d.value {
+			case "always":
+				prog.Autorestart = true
+			case "no":
+				prog.Autorestart = false
+			case "on-failure":
+				prog.SetAutorestart("unexpected")
+			default:
+				prog.Autorestart = true
+				warn("Restart=%s has no exact equivalent, mapped to autorestart=true", d.value)
+			}
+		case "KillSignal":
+			prog.StopSignal = strings.TrimPrefix(d.value, "SIG")
+		case "TimeoutStopSec":
+			if secs, ok := parseUnitSeconds(d.value); ok {
+				prog.StopWaitSecs = secs
+			} else {
+				warn("TimeoutStopSec=%s could not be converted", d.value)
+			}
+		case "StandardOutput", "StandardError":
+			path, ok := unitOutputFile(d.value)
+			if !ok {
+				warn("%s=%s is not supported", d.key, d.value)
+				continue
+			}
+			if d.key == "StandardOutput" {
+				prog.StdoutLogfile = path
+			} else {
+				prog.StderrLogfile = path
+			}
+		case "Type":
+			if d.value != "simple" && d.value != "exec" {
+				warn("Type=%s: supervisord expects the process to stay in the foreground", d.value)
+			}
+		default:
+			warn("%s=%s is not supported", d.key, d.value)
+		}
+	}
+
+	if prog.Command == "" {
+		return nil, warnings, fmt.Errorf("unit has no ExecStart")
+	}
+
+	return prog, warnings, nil
+}
+
+// UnitName returns the program name for a unit file path
+func UnitName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ".service")
+}
+
+// parseUnitFile parses a systemd unit into directives, joining continuation lines
+func parseUnitFile(data []byte) ([]unitDirective, error) {
+	var directives []unitDirective
+	var section, pending string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Lines ending with a backslash continue on the next line
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSpace(strings.TrimSuffix(line, "\\")) + " "
+			continue
+		}
+		line = strings.TrimSpace(pending + line)
+		pending = ""
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		directives = append(directives, unitDirective{
+			section: section,
+			key:     strings.TrimSpace(key),
+			value:   strings.TrimSpace(value),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading unit: %w", err)
+	}
+	return directives, nil
+}
+
+// splitUnitWords splits a value into words, honoring quotes and backslash escapes in double quotes
+func splitUnitWords(value string) []string {
+	var words []string
+	var current strings.Builder
+	var quote rune
+	inWord, escaped := false, false
+
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words
+}
+
+// readEnvironmentFile reads KEY=VALUE pairs from an environment file
+func readEnvironmentFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		env[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), "\"'")
+	}
+	return env, nil
+}
+
+// parseUnitSeconds parses a systemd time span like "30", "30s" or "1min 30s"
+func parseUnitSeconds(value string) (int, bool) {
+	if secs, err := strconv.Atoi(value); err == nil {
+		return secs, true
+	}
+
+	// Convert systemd units into something time.ParseDuration understands
+	replacer := strings.NewReplacer(" ", "", "min", "m", "sec", "s", "hour", "h")
+	d, err := time.ParseDuration(replacer.Replace(value))
+	if err != nil {
+		return 0, false
+	}
+	return int(d.Seconds()), true
+}
+
+// unitOutputFile returns the file of a StandardOutput/StandardError=file:/append: value
+func unitOutputFile(value string) (string, bool) {
+	for _, prefix := range []string{"append:", "file:", "truncate:"} {
+		if strings.HasPrefix(value, prefix) {
+			return strings.TrimPrefix(value, prefix), true
+		}
+	}
+	return "", false
+}
+
+// GenerateSystemdUnit converts a program into an equivalent systemd service unit
+// Settings without a systemd equivalent are returned as notes prefixed with the program name.
+func GenerateSystemdUnit(prog *ProcessConfig) (string, []string) {
+	var notes []string
+	note := func(format string, args ...interface{}) {
+		notes = append(notes, prog.Name+": "+fmt.Sprintf(format, args...))
+	}
+
+	// Expand the supervisord expressions and escape the remaining % so systemd
+	// doesn't read them as unit specifiers
+	proc := &Process{Name: prog.Name, Config: prog}
+	unitValue := func(option, value string) string {
+		if strings.Contains(value, "%(ENV_") {
+			note("%s uses %%(ENV_...)s, expanded from the current environment", option)
+		}
+		expanded := expandLogPath(value, proc, nil)
+		if logPathVarRe.MatchString(expanded) {
+			note("%s has supervisord expressions that couldn't be expanded, check %q", option, expanded)
+		}
+		return strings.ReplaceAll(expanded, "%", "%%")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[Unit]\n")
+	sb.WriteString(fmt.Sprintf("Description=%s (converted from supervisord)\n", strings.ReplaceAll(prog.Name, "%", "%%")))
+	sb.WriteString("After=network.target\n")
+	if prog.StartRetries > 0 {
+		sb.WriteString(fmt.Sprintf("StartLimitBurst=%d\n", prog.StartRetries))
+		note("startretries=%d mapped to StartLimitBurst, which counts restarts per interval", prog.StartRetries)
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("[Service]\n")
+	sb.WriteString("Type=simple\n")
+	if !strings.HasPrefix(prog.Command, "/") {
+		note("ExecStart needs an absolute path, check %q", prog.Command)
+	}
+	sb.WriteString(fmt.Sprintf("ExecStart=%s\n", unitValue("command", prog.Command)))
+	if prog.Directory != "" {
+		sb.WriteString(fmt.Sprintf("WorkingDirectory=%s\n", unitValue("directory", prog.Directory)))
+	}
+	if prog.User != "" {
+		sb.WriteString(fmt.Sprintf("User=%s\n", prog.User))
+	}
+	for _, key := range SortedKeys(prog.Environment) {
+		env := unitQuoteReplacer.Replace(unitValue("environment", prog.Environment[key]))
+		sb.WriteString(fmt.Sprintf("Environment=\"%s=%s\"\n", key, env))
+	}
+	if prog.Autorestart {
+		sb.WriteString("Restart=always\n")
+	} else if prog.RestartUnexpected {
+		sb.WriteString("Restart=on-failure\n")
+	} else {
+		sb.WriteString("Restart=no\n")
+	}
+	if prog.StopSignal != "" {
+		sb.WriteString(fmt.Sprintf("KillSignal=SIG%s\n", strings.TrimPrefix(strings.ToUpper(prog.StopSignal), "SIG")))
+	}
+	if prog.StopWaitSecs > 0 {
+		sb.WriteString(fmt.Sprintf("TimeoutStopSec=%d\n", prog.StopWaitSecs))
+	}
+
+	// Log redirection
+	if output, ok := unitOutput(unitValue("stdout_logfile", prog.StdoutLogfile)); ok {
+		sb.WriteString(fmt.Sprintf("StandardOutput=%s\n", output))
+	} else {
+		note("stdout_logfile=AUTO has no equivalent, output goes to the journal")
+	}
+	if strings.ToLower(prog.Extra["redirect_stderr"]) == "true" {
+		sb.WriteString("StandardError=inherit\n")
+	} else if output, ok := unitOutput(unitValue("stderr_logfile", prog.StderrLogfile)); ok {
+		sb.WriteString(fmt.Sprintf("StandardError=%s\n", output))
+	} else {
+		note("stderr_logfile=AUTO has no equivalent, output goes to the journal")
+	}
+	if prog.StdoutLogfileMaxBytes > 0 || prog.StdoutLogfileBackups > 0 ||
+		prog.StderrLogfileMaxBytes > 0 || prog.StderrLogfileBackups > 0 {
+		note("log rotation (*_logfile_maxbytes, *_logfile_backups) has no equivalent, use logrotate")
+	}
+
+	// Options without a direct counterpart
+	if prog.StartSecs > 0 {
+		note("startsecs=%d has no equivalent", prog.StartSecs)
+	}
+	if prog.Priority > 0 {
+		note("priority=%d has no equivalent, use After=/Before= to order services", prog.Priority)
+	}
+	for _, key := range SortedKeys(prog.Extra) {
+		switch key {
+		case "redirect_stderr":
+		case "umask":
+			sb.WriteString(fmt.Sprintf("UMask=%s\n", prog.Extra[key]))
+		case "stopasgroup", "killasgroup":
+			note("%s is the default in systemd (the whole control group is stopped)", key)
+		default:
+			note("%s=%s has no equivalent", key, prog.Extra[key])
+		}
+	}
+
+	// Programs started by supervisord on boot are enabled for the default target
+	if prog.Autostart {
+		sb.WriteString("\n[Install]\nWantedBy=multi-user.target\n")
+	} else {
+		note("autostart=false: the unit has no [Install] section and won't be enabled")
+	}
+
+	return sb.String(), notes
+}
+
+// unitQuoteReplacer escapes a value for a double-quoted unit word
+var unitQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// unitOutput converts a supervisord logfile setting to a StandardOutput/StandardError value
+func unitOutput(logfile string) (string, bool) {
+	switch strings.ToUpper(logfile) {
+	case "", "AUTO":
+		return "", false
+	case "NONE":
+		return "null", true
+	}
+	return "append:" + logfile, true
+}
@@ -0,0 +1,124 @@
+package supervisor
+
+import (
+	"strings"
+	"testing"
+)
+
+// unitSection returns the lines of a section of a generated unit
+func unitSection(unit, section string) []string {
+	var lines []string
+	current := ""
+	for _, line := range strings.Split(unit, "\n") {
+		if strings.HasPrefix(line, "[") {
+			current = strings.Trim(line, "[]")
+			continue
+		}
+		if current == section && line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func hasLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGenerateSystemdUnitEscapesPercent(t *testing.T) {
+	prog := &ProcessConfig{
+		Name:          "web",
+		Command:       "/usr/bin/date +%Y-%m-%d",
+		Directory:     "/srv/100%",
+		StdoutLogfile: "/var/log/%(program_name)s.log",
+		StderrLogfile: "/var/log/50%%.log",
+	}
+	unit, _ := GenerateSystemdUnit(prog)
+	service := unitSection(unit, "Service")
+
+	for _, want := range []string{
+		"ExecStart=/usr/bin/date +%%Y-%%m-%%d",
+		"WorkingDirectory=/srv/100%%",
+		"StandardOutput=append:/var/log/web.log",
+		"StandardError=append:/var/log/50%%.log",
+	} {
+		if !hasLine(service, want) {
+			t.Errorf("missing %q in unit:\n%s", want, unit)
+		}
+	}
+
+	parsed, _, err := ParseSystemdUnit([]byte(unit), "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Command != prog.Command || parsed.Directory != prog.Directory {
+		t.Errorf("parsed command %q in %q, want %q in %q", parsed.Command, parsed.Directory, prog.Command, prog.Directory)
+	}
+}
+
+func TestGenerateSystemdUnitNotesUnknownExpressions(t *testing.T) {
+	prog := &ProcessConfig{Name: "web", Command: "/usr/bin/web --id %(unknown)s"}
+	unit, notes := GenerateSystemdUnit(prog)
+
+	if !hasLine(unitSection(unit, "Service"), "ExecStart=/usr/bin/web --id %%(unknown)s") {
+		t.Errorf("unknown expression should be kept literally:\n%s", unit)
+	}
+	found := false
+	for _, note := range notes {
+		found = found || strings.Contains(note, "couldn't be expanded")
+	}
+	if !found {
+		t.Errorf("expected a note about the unknown expression, got %q", notes)
+	}
+}
+
+func TestGenerateSystemdUnitQuotesEnvironment(t *testing.T) {
+	prog := &ProcessConfig{
+		Name:    "web",
+		Command: "/usr/bin/web",
+		Environment: map[string]string{
+			"GREETING": `say "hi"`,
+			"PATTERN":  `C:\tmp`,
+			"RATE":     "5%",
+		},
+	}
+	unit, _ := GenerateSystemdUnit(prog)
+	service := unitSection(unit, "Service")
+
+	for _, want := range []string{
+		`Environment="GREETING=say \"hi\""`,
+		`Environment="PATTERN=C:\\tmp"`,
+		`Environment="RATE=5%%"`,
+	} {
+		if !hasLine(service, want) {
+			t.Errorf("missing %s in unit:\n%s", want, unit)
+		}
+	}
+
+	parsed, _, err := ParseSystemdUnit([]byte(unit), "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range prog.Environment {
+		if parsed.Environment[key] != value {
+			t.Errorf("parsed %s=%q, want %q", key, parsed.Environment[key], value)
+		}
+	}
+}
+
+func TestGenerateSystemdUnitStartLimitInUnitSection(t *testing.T) {
+	prog := &ProcessConfig{Name: "web", Command: "/usr/bin/web", StartRetries: 5}
+	unit, _ := GenerateSystemdUnit(prog)
+
+	if !hasLine(unitSection(unit, "Unit"), "StartLimitBurst=5") {
+		t.Errorf("StartLimitBurst should be in [Unit]:\n%s", unit)
+	}
+	if strings.Contains(strings.Join(unitSection(unit, "Service"), "\n"), "StartLimitBurst") {
+		t.Errorf("StartLimitBurst should not be in [Service]:\n%s", unit)
+	}
+}
@@ -1,5 +1,13 @@
 package supervisor
 
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
 // DefaultTemplate is the default template for creating new supervisord processes
 const DefaultTemplate = `[program:{{name}}]
 command={{command}}
@@ -20,3 +28,206 @@ priority=999
 stopsignal=TERM
 stopwaitsecs=30
 `
+
+// builtinTemplates are available even without a templates directory
+// Placeholders may carry a default value: {{command:python3 worker.py}}
+var builtinTemplates = []Template{
+	{Name: "default", Text: DefaultTemplate},
+	{Name: "python-worker", Text: `[program:{{name}}]
+command={{command:python3 -u worker.py}}
+directory={{directory}}
+user={{user}}
+autostart=true
+autorestart=true
+startsecs=5
+startretries=3
+stdout_logfile={{stdout_logfile}}
+stderr_logfile={{stderr_logfile}}
+stdout_logfile_maxbytes=10MB
+stdout_logfile_backups=5
+stderr_logfile_maxbytes=10MB
+stderr_logfile_backups=5
+environment=PYTHONUNBUFFERED=1
+stopsignal=TERM
+stopwaitsecs=30
+`},
+	{Name: "node-web", Text: `[program:{{name}}]
+command={{command:node server.js}}
+directory={{directory}}
+user={{user}}
+autostart=true
+autorestart=true
+startsecs=5
+startretries=3
+stdout_logfile={{stdout_logfile}}
+stderr_logfile={{stderr_logfile}}
+stdout_logfile_maxbytes=10MB
+stdout_logfile_backups=5
+stderr_logfile_maxbytes=10MB
+stderr_logfile_backups=5
+environment=NODE_ENV=production,PORT={{port:3000}}
+stopsignal=TERM
+stopwaitsecs=10
+`},
+	{Name: "queue-consumer", Text: `[program:{{name}}]
+command={{command:php artisan queue:work --sleep=3 --tries=3}}
+directory={{directory}}
+user={{user}}
+numprocs={{numprocs:2}}
+process_name=%(program_name)s_%(process_num)02d
+autostart=true
+autorestart=true
+startsecs=1
+stdout_logfile={{stdout_logfile}}
+stderr_logfile={{stderr_logfile}}
+stdout_logfile_maxbytes=10MB
+stdout_logfile_backups=5
+stopasgroup=true
+killasgroup=true
+stopwaitsecs=3600
+`},
+}
+
+// Template is a named program template with {{placeholder}} variables
+type Template struct {
+	Name string
+	Text string
+	Path string // Empty for built-in templates
+}
+
+// TemplateFields are the placeholders always asked for when using a template
+var TemplateFields = []string{"name", "command", "directory", "user", "log_dir"}
+
+var placeholderRe = regexp.MustCompile(`\{\{([a-z_]+)(?::([^}]*))?\}\}`)
+
+// TemplatesDir returns the directory holding user-defined templates
+func TemplatesDir() string {
+	return filepath.Join(ConfigDir(), "templates")
+}
+
+// LoadTemplates returns the built-in templates followed by user templates
+// from TemplatesDir() (*.conf). A user template replaces a built-in one with the same name.
+func LoadTemplates() []Template {
+	templates := append([]Template{}, builtinTemplates...)
+
+	files, _ := filepath.Glob(filepath.Join(TemplatesDir(), "*.conf"))
+	sort.Strings(files)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		tmpl := Template{
+			Name: strings.TrimSuffix(filepath.Base(file), ".conf"),
+			Text: string(data),
+			Path: file,
+		}
+
+		replaced := false
+		for i := range templates {
+			if templates[i].Name == tmpl.Name {
+				templates[i] = tmpl
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			templates = append(templates, tmpl)
+		}
+	}
+
+	return templates
+}
+
+// Placeholders returns the placeholders asked for by the template in order,
+// starting with TemplateFields, along with their default values
+func (t Template) Placeholders() ([]string, map[string]string) {
+	defaults := DefaultTemplateVars()
+	names := append([]string{}, TemplateFields...)
+
+	for _, match := range placeholderRe.FindAllStringSubmatch(t.Text, -1) {
+		key, value := match[1], match[2]
+		if value != "" {
+			defaults[key] = value
+		}
+		if _, derived := derivedVars[key]; derived {
+			continue
+		}
+		known := false
+		for _, name := range names {
+			if name == key {
+				known = true
+				break
+			}
+		}
+		if !known {
+			names = append(names, key)
+		}
+	}
+
+	return names, defaults
+}
+
+// derivedVars are computed from other variables unless set explicitly
+var derivedVars = map[string]func(vars map[string]string) string{
+	"stdout_logfile": func(vars map[string]string) string {
+		return filepath.Join(vars["log_dir"], vars["name"]+".log")
+	},
+	"stderr_logfile": func(vars map[string]string) string {
+		return filepath.Join(vars["log_dir"], vars["name"]+"-error.log")
+	},
+}
+
+// DefaultTemplateVars returns sensible defaults for template placeholders
+func DefaultTemplateVars() map[string]string {
+	directory, err := os.Getwd()
+	if err != nil {
+		directory = "/path/to/directory"
+	}
+
+	logDir := "/var/log"
+	if stat, err := os.Stat("/var/log/supervisor"); err == nil && stat.IsDir() {
+		logDir = "/var/log/supervisor"
+	}
+
+	return map[string]string{
+		"name":      "process-name",
+		"command":   "/path/to/command",
+		"directory": directory,
+		"user":      CurrentUser(),
+		"log_dir":   logDir,
+	}
+}
+
+// RenderTemplate replaces {{placeholders}} in text with vars
+// Lines of options left without a value are dropped
+func RenderTemplate(text string, vars map[string]string) string {
+	values := make(map[string]string)
+	for key, value := range vars {
+		values[key] = value
+	}
+	for key, derive := range derivedVars {
+		if values[key] == "" {
+			values[key] = derive(values)
+		}
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		rendered := placeholderRe.ReplaceAllStringFunc(line, func(match string) string {
+			parts := placeholderRe.FindStringSubmatch(match)
+			if value := values[parts[1]]; value != "" {
+				return value
+			}
+			return parts[2]
+		})
+
+		// Drop options whose value came out empty, e.g. "environment="
+		if rendered != line && strings.HasSuffix(strings.TrimSpace(rendered), "=") {
+			continue
+		}
+		lines = append(lines, rendered)
+	}
+
+	return strings.Join(lines, "\n")
+}
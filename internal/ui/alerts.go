@@ -0,0 +1,296 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+const (
+	alertLines          = 1000             // Lines of each log kept when watching for alerts
+	alertHistory        = 100              // Alerts kept for the alerts list
+	alertCommandTimeout = 30 * time.Second // Alert commands running longer are killed
+)
+
+// Alert is a log line that matched an alert rule
+type Alert struct {
+	Rule    *supervisor.AlertRule
+	Process string
+	Stream  string
+	Path    string
+	Line    string
+	Time    time.Time
+}
+
+// alertCommandMsg reports an alert command that failed
+type alertCommandMsg struct {
+	alert  Alert
+	err    error
+	output string
+}
+
+// alertLog tails a log file for lines matching alert rules
+type alertLog struct {
+	reader   *tailReader
+	consumed int
+	resets   int
+}
+
+// alertTarget is a process whose output is written to a watched log
+type alertTarget struct {
+	process string
+	streams []string // Streams of the process written to the log
+	rules   []*supervisor.AlertRule
+}
+
+// AlertWatcher watches the logs of the processes for lines matching alert rules
+// Only lines written while god is running raise alerts. Scan reads the logs in a
+// background command, one at a time; the other methods run on the UI goroutine.
+type AlertWatcher struct {
+	rules   []*supervisor.AlertRule
+	logs    map[string]*alertLog
+	alerts  []Alert              // Recent alerts, oldest first
+	counts  map[string]int       // Alerts per process since they were last acknowledged
+	unseen  int                  // Alerts since they were last acknowledged
+	lastRun map[string]time.Time // When the command of a rule last ran per process
+}
+
+// NewAlertWatcher creates a watcher for the given rules
+func NewAlertWatcher(rules []*supervisor.AlertRule) *AlertWatcher {
+	return &AlertWatcher{
+		rules:   rules,
+		logs:    make(map[string]*alertLog),
+		counts:  make(map[string]int),
+		lastRun: make(map[string]time.Time),
+	}
+}
+
+// Scan reads new lines of the processes' logs and returns the alerts they raised
+// The alerts are added to the list and counters by Record.
+func (w *AlertWatcher) Scan(processes []*supervisor.Process) []Alert {
+	if len(w.rules) == 0 {
+		return nil
+	}
+
+	// Programs may share a logfile: it is read once and matched against the rules of each
+	targets := make(map[string][]alertTarget)
+	var paths []string
+	for _, proc := range processes {
+		for _, stream := range []string{streamStdout, streamStderr} {
+			path := proc.Logs.Stdout
+			if stream == streamStderr {
+				path = proc.Logs.Stderr
+			}
+			if path == "" {
+				continue
+			}
+
+			// With redirect_stderr the stdout log holds both streams
+			streams := []string{stream}
+			if stream == streamStdout && proc.Logs.RedirectStderr {
+				streams = append(streams, streamStderr)
+			}
+			rules := w.rulesFor(proc.Name, streams)
+			if len(rules) == 0 {
+				continue
+			}
+			if _, ok := targets[path]; !ok {
+				paths = append(paths, path)
+			}
+			targets[path] = append(targets[path], alertTarget{process: proc.Name, streams: streams, rules: rules})
+		}
+	}
+
+	var raised []Alert
+	for _, path := range paths {
+		log, ok := w.logs[path]
+		if !ok {
+			log = &alertLog{reader: newTailReader(path, alertLines)}
+			w.logs[path] = log
+		}
+		for _, line := range log.read() {
+			text := plainText(line)
+			for _, target := range targets[path] {
+				for _, rule := range target.rules {
+					if rule.Pattern.MatchString(text) {
+						raised = append(raised, Alert{
+							Rule:    rule,
+							Process: target.process,
+							Stream:  strings.Join(target.streams, "+"),
+							Path:    path,
+							Line:    text,
+							Time:    time.Now(),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	// Forget logs of removed programs
+	for path := range w.logs {
+		if _, ok := targets[path]; !ok {
+			delete(w.logs, path)
+		}
+	}
+	return raised
+}
+
+// Record adds alerts raised by Scan to the list and counters
+func (w *AlertWatcher) Record(raised []Alert) {
+	for _, alert := range raised {
+		w.counts[alert.Process]++
+	}
+	w.unseen += len(raised)
+	w.alerts = append(w.alerts, raised...)
+	if len(w.alerts) > alertHistory {
+		w.alerts = append([]Alert{}, w.alerts[len(w.alerts)-alertHistory:]...)
+	}
+}
+
+// rulesFor returns the rules applying to a process and any of the streams
+func (w *AlertWatcher) rulesFor(process string, streams []string) []*supervisor.AlertRule {
+	var rules []*supervisor.AlertRule
+	for _, rule := range w.rules {
+		if !rule.MatchesProcess(process) {
+			continue
+		}
+		for _, stream := range streams {
+			if rule.MatchesStream(stream) {
+				rules = append(rules, rule)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// read returns the lines appended since the last read
+// Lines already in the log on the first read don't count as new, and neither
+// do the lines of a later full read, since they may have been seen before.
+func (l *alertLog) read() []string {
+	if _, err := l.reader.Read(); err != nil {
+		return nil
+	}
+	if l.reader.resets != l.resets {
+		l.resets = l.reader.resets
+		l.consumed = l.reader.total
+		return nil
+	}
+
+	lines := l.reader.lines
+	added := min(l.reader.total-l.consumed, len(lines))
+	l.consumed = l.reader.total
+	return lines[len(lines)-added:]
+}
+
+// Counts returns the alerts per process since they were last acknowledged
+func (w *AlertWatcher) Counts() map[string]int {
+	return w.counts
+}
+
+// Latest returns the most recent alert that wasn't acknowledged, and how many there are
+func (w *AlertWatcher) Latest() (Alert, int) {
+	if w.unseen == 0 || len(w.alerts) == 0 {
+		return Alert{}, 0
+	}
+	return w.alerts[len(w.alerts)-1], w.unseen
+}
+
+// Acknowledge clears the banner and the counters
+func (w *AlertWatcher) Acknowledge() {
+	w.unseen = 0
+	w.counts = make(map[string]int)
+}
+
+// commands returns commands running the rules of the alerts, at most once
+// per rule and process within the rule's cooldown
+func (w *AlertWatcher) commands(alerts []Alert) []tea.Cmd {
+	var cmds []tea.Cmd
+	for _, alert := range alerts {
+		if alert.Rule.Command == "" {
+			continue
+		}
+		key := alert.Rule.Name + "\x00" + alert.Process
+		if last, ok := w.lastRun[key]; ok && alert.Time.Sub(last) < alert.Rule.Cooldown {
+			continue
+		}
+		w.lastRun[key] = alert.Time
+		cmds = append(cmds, runAlertCommand(alert))
+	}
+	return cmds
+}
+
+// runAlertCommand runs the command of an alert's rule with the match in its environment
+func runAlertCommand(alert Alert) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), alertCommandTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", alert.Rule.Command)
+		cmd.Env = append(os.Environ(),
+			"GOD_ALERT="+alert.Rule.Name,
+			"GOD_SEVERITY="+alert.Rule.Severity,
+			"GOD_PROGRAM="+alert.Process,
+			"GOD_STREAM="+alert.Stream,
+			"GOD_LOGFILE="+alert.Path,
+			"GOD_LINE="+alert.Line,
+			"GOD_TIME="+alert.Time.Format(time.RFC3339),
+		)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		return alertCommandMsg{alert: alert, err: err, output: strings.TrimSpace(string(output))}
+	}
+}
+
+// severityStyle returns the style alerts of a severity are shown in
+func severityStyle(severity string) lipgloss.Style {
+	switch severity {
+	case supervisor.SeverityCritical:
+		return errorStyle.Bold(true)
+	case supervisor.SeverityWarning:
+		return warningStyle
+	}
+	return pendingStyle
+}
+
+// alertBanner renders the latest alert for the status bar, empty if there is none
+func (w *AlertWatcher) alertBanner(width int) string {
+	alert, count := w.Latest()
+	if count == 0 {
+		return ""
+	}
+	text := fmt.Sprintf("⚑ %s: %s", alert.Process, alert.Line)
+	if count > 1 {
+		// The count comes first, long lines are truncated
+		text = fmt.Sprintf("⚑ %d alerts, latest %s: %s", count, alert.Process, alert.Line)
+	}
+	return severityStyle(alert.Rule.Severity).Render(truncateLine(text, width)) + ", !: alerts"
+}
+
+// alertList describes the most recent alerts, newest first
+func (w *AlertWatcher) alertList(limit int) string {
+	if len(w.rules) == 0 {
+		return fmt.Sprintf("No alert rules, add them to %s", supervisor.AlertsFile())
+	}
+	if len(w.alerts) == 0 {
+		return fmt.Sprintf("No alerts yet (%d rules from %s)", len(w.rules), supervisor.AlertsFile())
+	}
+
+	var lines []string
+	for i := len(w.alerts) - 1; i >= 0 && len(lines) < limit; i-- {
+		alert := w.alerts[i]
+		lines = append(lines, fmt.Sprintf("%s [%s] %s %s (%s): %s", alert.Time.Format("15:04:05"),
+			alert.Rule.Severity, alert.Rule.Name, alert.Process, alert.Stream, alert.Line))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// colorLogs controls whether ANSI colors in program logs are shown or stripped
+var colorLogs = true
+
+// SetColorLogs shows or strips ANSI colors in program logs
+func SetColorLogs(enabled bool) {
+	colorLogs = enabled
+}
+
+// sanitizeLine removes escape sequences and control characters that would
+// corrupt the screen, like cursor movement or clearing. SGR (color) sequences
+// are kept unless colors are disabled. Text overwritten by a carriage return is dropped.
+func sanitizeLine(line string) string {
+	if !hasControl(line) {
+		return line
+	}
+
+	var b strings.Builder
+	var state byte
+	for len(line) > 0 {
+		seq, _, n, newState := ansi.DecodeSequence(line, state, nil)
+		state = newState
+		line = line[n:]
+
+		switch {
+		case seq == "\t":
+			b.WriteString(seq)
+		case seq == "\r":
+			// Progress bars redraw the line: keep what was written last
+			b.Reset()
+		case ansi.HasCsiPrefix(seq) && strings.HasSuffix(seq, "m"):
+			if colorLogs {
+				b.WriteString(seq)
+			}
+		case isControl(seq):
+			// Other escape sequences and control characters are dropped
+		default:
+			b.WriteString(seq)
+		}
+	}
+	return b.String()
+}
+
+// hasControl returns true if line contains control characters other than tabs
+func hasControl(line string) bool {
+	for i := 0; i < len(line); i++ {
+		if c := line[i]; (c < 0x20 && c != '\t') || c == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// isControl returns true for escape sequences and control characters
+func isControl(seq string) bool {
+	if seq == "" {
+		return true
+	}
+	c := seq[0]
+	return c < 0x20 || c == 0x7f
+}
+
+// hasColors returns true if a sanitized line contains color sequences
+func hasColors(line string) bool {
+	return strings.IndexByte(line, 0x1b) >= 0
+}
+
+// plainText returns a line without color sequences, for matching
+func plainText(line string) string {
+	if !hasColors(line) {
+		return line
+	}
+	return ansi.Strip(line)
+}
+
+// renderLogLine renders a log line with style, or with its own colors if it has any
+// A reset is appended so colors left open by the program don't bleed into the UI.
+func renderLogLine(line string, style lipgloss.Style) string {
+	if hasColors(line) {
+		return line + ansi.ResetStyle
+	}
+	return style.Render(line)
+}
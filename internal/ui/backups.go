@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxBackupHits = 1000 // Search results kept when searching all backups
+
+// backupSuffixRe matches the suffix of rotated logs: supervisord's .1 .. .N,
+// logrotate's dateext (-20240102) and either of them gzipped
+var backupSuffixRe = regexp.MustCompile(`^(\.(\d+)|-(\d{8,10}))(\.gz)?$`)
+
+// logFile is a log file or one of its rotated backups
+type logFile struct {
+	path       string
+	size       int64
+	modTime    time.Time
+	compressed bool
+	first      time.Time // Time of the first line with a timestamp, zero if unknown
+	last       time.Time // Time of the last line with a timestamp, zero if unknown
+}
+
+// label returns a short name for the file relative to the current log
+func (f logFile) label(current string) string {
+	if f.path == current {
+		return "current"
+	}
+	return strings.TrimPrefix(f.path, current)
+}
+
+// timeRange describes the time span of the file's lines
+func (f logFile) timeRange() string {
+	const layout = "2006-01-02 15:04"
+	switch {
+	case !f.first.IsZero() && !f.last.IsZero():
+		return f.first.Format(layout) + " – " + f.last.Format(layout)
+	case !f.last.IsZero():
+		return "until " + f.last.Format(layout)
+	}
+	return "last written " + f.modTime.Format(layout)
+}
+
+// backupHit is a line matching a search across a log and its backups
+type backupHit struct {
+	file      string
+	line      int // Line number in the file (1-based)
+	fromEnd   int // Number of matching lines after this one in the file
+	text      string
+	fileIndex int
+}
+
+// findLogFiles returns the log file followed by its rotated backups, newest first
+func findLogFiles(path string) []logFile {
+	var files []logFile
+	if info, err := os.Stat(path); err == nil {
+		files = append(files, logFile{path: path, size: info.Size(), modTime: info.ModTime()})
+	}
+
+	type backup struct {
+		logFile
+		index int
+	}
+	var backups []backup
+	dotted, _ := filepath.Glob(globEscape(path) + ".*")
+	dated, _ := filepath.Glob(globEscape(path) + "-*")
+	for _, match := range append(dotted, dated...) {
+		parts := backupSuffixRe.FindStringSubmatch(strings.TrimPrefix(match, path))
+		if parts == nil {
+			continue
+		}
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		// Numbered backups grow older with the number, dated ones with the date
+		index, _ := strconv.Atoi(parts[2])
+		if parts[3] != "" {
+			date, _ := strconv.Atoi(parts[3])
+			index = -date
+		}
+		backups = append(backups, backup{
+			logFile: logFile{
+				path:       match,
+				size:       info.Size(),
+				modTime:    info.ModTime(),
+				compressed: parts[4] != "",
+			},
+			index: index,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].index < backups[j].index })
+
+	for _, b := range backups {
+		files = append(files, b.logFile)
+	}
+	for i := range files {
+		files[i].first, files[i].last = fileTimeRange(files[i])
+	}
+	return files
+}
+
+// globEscape escapes glob metacharacters in a path
+func globEscape(path string) string {
+	replacer := strings.NewReplacer("*", "\\*", "?", "\\?", "[", "\\[", "]", "\\]")
+	return replacer.Replace(path)
+}
+
+// openLogFile opens a log file for reading, decompressing .gz files
+func openLogFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{gz, file}, nil
+}
+
+// scanLogFile calls fn for every line of a log file, decompressing .gz files
+// Lines longer than maxLineBytes are clipped. Scanning stops when fn returns false.
+func scanLogFile(path string, fn func(line string) bool) error {
+	rc, err := openLogFile(path)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	reader := bufio.NewReaderSize(rc, tailBlockSize)
+	for {
+		line, err := readLongLine(reader)
+		if len(line) > 0 || err == nil {
+			if !fn(clipLine(line)) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+}
+
+// readLongLine reads a line of any length, keeping at most maxLineBytes+1 bytes of it
+func readLongLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if len(line) <= maxLineBytes {
+			line = append(line, chunk...)
+		}
+		if err != nil || !isPrefix {
+			return line, err
+		}
+	}
+}
+
+// fileTimeRange returns the times of the first and last timestamped lines
+func fileTimeRange(f logFile) (time.Time, time.Time) {
+	var first, last time.Time
+
+	if f.compressed {
+		// Compressed files can only be read from the start
+		scanLogFile(f.path, func(line string) bool {
+			if t, ok := lineTime(line); ok {
+				if first.IsZero() {
+					first = t
+				}
+				last = t
+			}
+			return true
+		})
+		return first, last
+	}
+
+	// Plain files: look at the first lines, then at the last ones
+	count := 0
+	scanLogFile(f.path, func(line string) bool {
+		count++
+		if t, ok := lineTime(line); ok {
+			first = t
+			return false
+		}
+		return count < 100
+	})
+
+	reader := newTailReader(f.path, 100)
+	if _, err := reader.Read(); err == nil {
+		lines := reader.Lines()
+		for i := len(lines) - 1; i >= 0; i-- {
+			if t, ok := lineTime(lines[i]); ok {
+				last = t
+				break
+			}
+		}
+	}
+	return first, last
+}
+
+// searchLogFiles returns lines matching re in all files, oldest file first
+func searchLogFiles(files []logFile, re interface{ MatchString(string) bool }) ([]backupHit, bool, error) {
+	var hits []backupHit
+	truncated := false
+
+	for i := len(files) - 1; i >= 0; i-- {
+		var fileHits []backupHit
+		lineNum, matches := 0, 0
+		err := scanLogFile(files[i].path, func(line string) bool {
+			lineNum++
+			if re.MatchString(plainText(line)) {
+				// fromEnd holds the match's ordinal until the total is known
+				fileHits = append(fileHits, backupHit{file: files[i].path, line: lineNum, fromEnd: matches, text: line, fileIndex: i})
+				matches++
+				if len(fileHits) >= 2*maxBackupHits {
+					fileHits = append([]backupHit{}, fileHits[len(fileHits)-maxBackupHits:]...)
+					truncated = true
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return hits, truncated, err
+		}
+		for j := range fileHits {
+			fileHits[j].fromEnd = matches - 1 - fileHits[j].fromEnd
+		}
+		hits = append(hits, fileHits...)
+		if len(hits) > 2*maxBackupHits {
+			hits = append([]backupHit{}, hits[len(hits)-maxBackupHits:]...)
+			truncated = true
+		}
+	}
+
+	// Keep the most recent hits
+	if len(hits) > maxBackupHits {
+		hits = hits[len(hits)-maxBackupHits:]
+		truncated = true
+	}
+	return hits, truncated, nil
+}
+
+// formatSize formats a file size for display
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024*1024:
+		return fmt.Sprintf("%.1fG", float64(size)/(1024*1024*1024))
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fM", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1fK", float64(size)/1024)
+	}
+	return fmt.Sprintf("%dB", size)
+}
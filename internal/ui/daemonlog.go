@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+// mentionStyle marks lines of supervisord's log mentioning the selected program
+var mentionStyle = lipgloss.NewStyle().Foreground(accentColor).Bold(true)
+
+// OpenDaemonLog shows supervisord's own log, marking the lines that mention
+// the selected process (spawned, exited, gave up, ...)
+func (m *LogViewerModel) OpenDaemonLog(path string, selected *supervisor.Process) {
+	m.Open(&supervisor.Process{Name: "supervisord", Logs: supervisor.LogFiles{Stdout: path}}, streamStdout)
+	m.daemon = true
+	m.mention = nil
+	m.mentionName = ""
+	if selected != nil {
+		m.mention = mentionPattern(selected.Name)
+		m.mentionName = selected.Name
+	}
+}
+
+// mentionPattern matches a process by its full name, its group or its process name
+// supervisord writes e.g. "spawned: 'worker_00' with pid 42" or "exited: web (exit status 1)".
+func mentionPattern(name string) *regexp.Regexp {
+	names := []string{regexp.QuoteMeta(name)}
+	if group, process, ok := strings.Cut(name, ":"); ok {
+		names = append(names, regexp.QuoteMeta(group), regexp.QuoteMeta(process))
+	}
+	return regexp.MustCompile(`(?:^|[^\w.-])(?:` + strings.Join(names, "|") + `)(?:$|[^\w.-])`)
+}
+
+// mentionCount returns the number of shown lines mentioning the selected process
+func (m *LogViewerModel) mentionCount() int {
+	count := 0
+	for _, line := range m.lines {
+		if m.mention.MatchString(plainText(line)) {
+			count++
+		}
+	}
+	return count
+}
+
+// mentionInfo describes the marked lines for the info line
+func (m *LogViewerModel) mentionInfo() string {
+	if m.mention == nil {
+		return ""
+	}
+	return fmt.Sprintf("  ● %d lines mention %s", m.mentionCount(), m.mentionName)
+}
@@ -5,16 +5,26 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/nicklasos/supervisord-tui/internal/supervisor"
 )
 
+const logLines = 6 // Number of lines to show from each log
+
+// redirectedLines is how many stdout lines are searched for errors when stderr is redirected to stdout
+const redirectedLines = 200
+
 // DetailModel represents the combined process info, error log, and stdout log section
 type DetailModel struct {
 	process   *supervisor.Process
+	pending   string // Kind of config change not applied yet, if any
 	errorLog  []string
 	stdoutLog []string
 	width     int
 	height    int
+
+	// A reader per log file shown, so refreshes only read what changed
+	readers map[string]*tailReader
 }
 
 // NewDetailModel creates a new detail model
@@ -22,6 +32,7 @@ func NewDetailModel() *DetailModel {
 	return &DetailModel{
 		errorLog:  []string{},
 		stdoutLog: []string{},
+		readers:   make(map[string]*tailReader),
 	}
 }
 
@@ -31,6 +42,11 @@ func (m *DetailModel) SetProcess(process *supervisor.Process) {
 	m.loadLogs()
 }
 
+// SetPending sets the kind of config change pending for the process
+func (m *DetailModel) SetPending(kind string) {
+	m.pending = kind
+}
+
 // SetSize sets the size of the detail view
 func (m *DetailModel) SetSize(width, height int) {
 	m.width = width
@@ -43,24 +59,64 @@ func (m *DetailModel) loadLogs() {
 	m.stdoutLog = []string{}
 
 	if m.process == nil {
+		clear(m.readers)
 		return
 	}
 
-	if m.process.Config == nil {
-		return
+	logs := m.process.Logs
+
+	// Drop the readers of logs that are no longer shown
+	for path := range m.readers {
+		if path != logs.Stderr && path != logs.Stdout {
+			delete(m.readers, path)
+		}
 	}
 
 	// Load error log
-	if m.process.Config.StderrLogfile != "" {
-		m.errorLog = readLastLines(m.process.Config.StderrLogfile, 6)
+	switch {
+	case logs.Stderr != "":
+		m.errorLog = m.readLastLines(logs.Stderr, logLines)
+	case logs.RedirectStderr && logs.Stdout != "":
+		// stderr is mixed into stdout, so show the errors and warnings written there
+		m.errorLog = problemLines(m.readLastLines(logs.Stdout, redirectedLines), logLines)
+		if len(m.errorLog) == 0 {
+			m.errorLog = []string{fmt.Sprintf("No errors in the last %d lines of stdout", redirectedLines)}
+		}
+	case logs.StderrNote != "":
+		m.errorLog = []string{logs.StderrNote}
 	}
 
 	// Load stdout log
-	if m.process.Config.StdoutLogfile != "" {
-		m.stdoutLog = readLastLines(m.process.Config.StdoutLogfile, 6)
+	switch {
+	case logs.Stdout != "":
+		m.stdoutLog = m.readLastLines(logs.Stdout, logLines)
+	case logs.StdoutNote != "":
+		m.stdoutLog = []string{logs.StdoutNote}
 	}
 }
 
+// readLastLines reads the last N lines from a file
+// Files are read from the end and cached, so repeated calls only read appended data
+func (m *DetailModel) readLastLines(filepath string, n int) []string {
+	reader, ok := m.readers[filepath]
+	if !ok || reader.limit < n {
+		reader = newTailReader(filepath, n)
+		m.readers[filepath] = reader
+	}
+
+	if _, err := reader.Read(); err != nil {
+		delete(m.readers, filepath)
+		return []string{fmt.Sprintf("Error: %v", err)}
+	}
+
+	// Return last N lines
+	lines := reader.Lines()
+	if len(lines) <= n {
+		return lines
+	}
+	return lines[len(lines)-n:]
+}
+
 // View renders the combined detail view
 func (m *DetailModel) View() string {
 	if m.process == nil {
@@ -83,6 +139,16 @@ func (m *DetailModel) View() string {
 	statusStyle := GetStatusStyle(m.process.Status)
 	lines = append(lines, labelStyle.Render("Status:")+" "+statusStyle.Render(m.process.Status))
 
+	// Config changed on disk but not applied
+	switch m.pending {
+	case supervisor.ChangeAdded:
+		lines = append(lines, labelStyle.Render("Pending:")+" "+pendingStyle.Render("added on disk, not loaded yet (u: apply)"))
+	case supervisor.ChangeChanged:
+		lines = append(lines, labelStyle.Render("Pending:")+" "+pendingStyle.Render("config changed on disk (u: apply)"))
+	case supervisor.ChangeRemoved:
+		lines = append(lines, labelStyle.Render("Pending:")+" "+pendingStyle.Render("removed from disk (u: apply)"))
+	}
+
 	// PID on its own line
 	if m.process.PID > 0 {
 		lines = append(lines, labelStyle.Render("PID:")+" "+valueStyle.Render(fmt.Sprintf("%d", m.process.PID)))
@@ -119,23 +185,26 @@ func (m *DetailModel) View() string {
 			}
 			lines = append(lines, labelStyle.Render("Dir:")+" "+valueStyle.Render(dir))
 		}
+
+		// Source file and line on its own line
+		if m.process.Config.SourceFile != "" {
+			source := fmt.Sprintf("%s:%d", m.process.Config.SourceFile, m.process.Config.StartLine)
+			lines = append(lines, labelStyle.Render("File:")+" "+valueStyle.Render(truncateLine(source, m.width-10)))
+		}
 	}
 
 	// Error Log Section
+	title := "Error Log"
+	if m.process.Logs.RedirectStderr {
+		title = "Error Log (from stdout, stderr is redirected)"
+	}
 	lines = append(lines, "")
-	lines = append(lines, titleStyle.Render("Error Log"))
+	lines = append(lines, titleStyle.Render(title))
 	lines = append(lines, "")
 	if len(m.errorLog) == 0 {
 		lines = append(lines, valueStyle.Foreground(subtleColor).Render("No error log available"))
 	} else {
-		maxLineWidth := m.width - 6
-		if maxLineWidth < 10 {
-			maxLineWidth = 10
-		}
-		for _, line := range m.errorLog {
-			truncated := truncateLine(line, maxLineWidth)
-			lines = append(lines, valueStyle.Foreground(errorColor).Render(truncated))
-		}
+		lines = append(lines, m.renderLog(m.errorLog)...)
 	}
 
 	// Stdout Log Section
@@ -145,20 +214,44 @@ func (m *DetailModel) View() string {
 	if len(m.stdoutLog) == 0 {
 		lines = append(lines, valueStyle.Foreground(subtleColor).Render("No stdout log available"))
 	} else {
-		maxLineWidth := m.width - 6
-		if maxLineWidth < 10 {
-			maxLineWidth = 10
-		}
-		for _, line := range m.stdoutLog {
-			truncated := truncateLine(line, maxLineWidth)
-			lines = append(lines, valueStyle.Render(truncated))
-		}
+		lines = append(lines, m.renderLog(m.stdoutLog)...)
 	}
 
 	content := strings.Join(lines, "\n")
 	return detailPanelStyle.Width(m.width).Height(m.height).Render(content)
 }
 
+// renderLog renders log lines colored by level and truncated to the panel
+// JSON lines are shown as fields, raw they are unreadable once truncated
+func (m *DetailModel) renderLog(log []string) []string {
+	maxLineWidth := max(10, m.width-6) // Account for borders (2) and padding (4)
+	levels := classifyLines(log)
+	lines := make([]string, len(log))
+	for i, line := range log {
+		lines[i] = renderLogText(line, maxLineWidth, lineStyle(levels[i]), true)
+	}
+	return lines
+}
+
+// truncateLine truncates a line to fit within maxWidth, adding "..." if truncated
+func truncateLine(line string, maxWidth int) string {
+	if maxWidth <= 0 {
+		return ""
+	}
+
+	// Measure the visible width, skipping color sequences and counting wide characters
+	if ansi.StringWidth(line) <= maxWidth {
+		return line
+	}
+
+	// Truncate and add ellipsis
+	if maxWidth <= 3 {
+		return "..."
+	}
+
+	return ansi.Truncate(line, maxWidth, "...")
+}
+
 // formatUptime formats a duration as a human-readable string
 func formatUptime(d time.Duration) string {
 	hours := int(d.Hours())
@@ -17,6 +17,10 @@ type EditorModel struct {
 	textarea textarea.Model
 	config   *supervisor.ProcessConfig
 	isNew    bool
+	title    string   // Overrides the default title when set
+	warnings []string // Notes shown below the editor (e.g. from an import)
+	filePath string   // Set when editing a whole config file instead of one program
+	original string   // File content when it was opened
 	width    int
 	height   int
 	errorMsg string
@@ -44,6 +48,9 @@ func (m *EditorModel) Init() tea.Cmd {
 // SetConfig sets the config to edit (nil for new entry with template)
 func (m *EditorModel) SetConfig(config *supervisor.ProcessConfig) {
 	m.errorMsg = ""
+	m.title = ""
+	m.warnings = nil
+	m.filePath = ""
 
 	if config == nil {
 		// New process - use template
@@ -60,6 +67,46 @@ func (m *EditorModel) SetConfig(config *supervisor.ProcessConfig) {
 	m.textarea.CursorEnd()
 }
 
+// SetDraft opens a generated config as a new entry for review before saving
+func (m *EditorModel) SetDraft(config *supervisor.ProcessConfig, title string, warnings []string) {
+	m.errorMsg = ""
+	m.filePath = ""
+	m.config = nil
+	m.isNew = true
+	m.title = title
+	m.warnings = warnings
+	m.textarea.SetValue(generateConfigText(config))
+	m.textarea.CursorEnd()
+}
+
+// SetFile opens a whole config file, with all of its sections, for editing
+func (m *EditorModel) SetFile(path, content string) {
+	m.SetConfig(nil)
+	m.isNew = false
+	m.filePath = path
+	m.original = content
+	m.title = fmt.Sprintf("Edit File: %s", path)
+	m.textarea.SetValue(content)
+	m.textarea.CursorEnd()
+}
+
+// FilePath returns the config file being edited as a whole (empty when editing one program)
+func (m *EditorModel) FilePath() string {
+	return m.filePath
+}
+
+// FileContent returns the edited file content and the content it was opened with
+func (m *EditorModel) FileContent() (string, string) {
+	return m.textarea.Value(), m.original
+}
+
+// SetTemplate opens a rendered template as a new entry
+func (m *EditorModel) SetTemplate(text string) {
+	m.SetConfig(nil)
+	m.textarea.SetValue(text)
+	m.textarea.CursorEnd()
+}
+
 // SetSize sets the size of the editor
 func (m *EditorModel) SetSize(width, height int) {
 	m.width = width
@@ -86,6 +133,11 @@ func (m *EditorModel) Validate() error {
 		return fmt.Errorf("configuration cannot be empty")
 	}
 
+	// Whole files are checked section by section
+	if m.filePath != "" {
+		return supervisor.ValidateConfigText(content)
+	}
+
 	// Try to parse to ensure it's valid
 	_, err := parseConfigText(content)
 	if err != nil {
@@ -96,9 +148,29 @@ func (m *EditorModel) Validate() error {
 }
 
 // GetConfig returns the config from the textarea content
+// Edits of an existing program keep pointing at the file it was loaded from
 func (m *EditorModel) GetConfig() (*supervisor.ProcessConfig, error) {
 	content := m.textarea.Value()
-	return parseConfigText(content)
+	config, err := parseConfigText(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.config != nil {
+		config.SourceFile = m.config.SourceFile
+		config.StartLine = m.config.StartLine
+		config.EndLine = m.config.EndLine
+	}
+
+	return config, nil
+}
+
+// OriginalName returns the name of the program being edited (empty for new entries)
+func (m *EditorModel) OriginalName() string {
+	if m.config == nil {
+		return ""
+	}
+	return m.config.Name
 }
 
 // SetError sets an error message
@@ -112,6 +184,9 @@ func (m *EditorModel) View() string {
 	if m.isNew {
 		title = "Add New Process"
 	}
+	if m.title != "" {
+		title = m.title
+	}
 
 	var content strings.Builder
 	content.WriteString(titleStyle.Render(title))
@@ -128,6 +203,15 @@ func (m *EditorModel) View() string {
 		content.WriteString("\n")
 	}
 
+	// Warnings
+	for _, warning := range m.warnings {
+		content.WriteString("\n")
+		content.WriteString(warningStyle.Render("⚠ " + warning))
+	}
+	if len(m.warnings) > 0 {
+		content.WriteString("\n")
+	}
+
 	// Help text
 	content.WriteString("\n")
 	helpText := "Shift+Enter: save | Esc: cancel"
@@ -138,25 +222,7 @@ func (m *EditorModel) View() string {
 
 // generateTemplateText generates the template text for a new process
 func generateTemplateText() string {
-	return `[program:process-name]
-command=/path/to/command
-directory=/path/to/directory
-user=nicklasos
-autostart=true
-autorestart=true
-startsecs=10
-startretries=3
-stdout_logfile=/var/log/process.log
-stderr_logfile=/var/log/process-error.log
-stdout_logfile_maxbytes=1MB
-stdout_logfile_backups=10
-stderr_logfile_maxbytes=1MB
-stderr_logfile_backups=10
-environment=KEY1=value1,KEY2=value2
-priority=999
-stopsignal=TERM
-stopwaitsecs=30
-`
+	return supervisor.RenderTemplate(supervisor.DefaultTemplate, supervisor.DefaultTemplateVars())
 }
 
 // generateConfigText generates config text from ProcessConfig
@@ -175,7 +241,7 @@ func generateConfigText(config *supervisor.ProcessConfig) string {
 		sb.WriteString(fmt.Sprintf("user=%s\n", config.User))
 	}
 	sb.WriteString(fmt.Sprintf("autostart=%v\n", config.Autostart))
-	sb.WriteString(fmt.Sprintf("autorestart=%v\n", config.Autorestart))
+	sb.WriteString(fmt.Sprintf("autorestart=%s\n", config.AutorestartValue()))
 	if config.StartSecs > 0 {
 		sb.WriteString(fmt.Sprintf("startsecs=%d\n", config.StartSecs))
 	}
@@ -195,7 +261,7 @@ func generateConfigText(config *supervisor.ProcessConfig) string {
 		sb.WriteString(fmt.Sprintf("stdout_logfile_backups=%d\n", config.StdoutLogfileBackups))
 	}
 	if config.StderrLogfileMaxBytes > 0 {
-		sb.WriteString(fmt.Sprintf("stderr_logfile_maxbytes=%s\n", formatBytes(config.StdoutLogfileMaxBytes)))
+		sb.WriteString(fmt.Sprintf("stderr_logfile_maxbytes=%s\n", formatBytes(config.StderrLogfileMaxBytes)))
 	}
 	if config.StderrLogfileBackups > 0 {
 		sb.WriteString(fmt.Sprintf("stderr_logfile_backups=%d\n", config.StderrLogfileBackups))
@@ -213,6 +279,9 @@ func generateConfigText(config *supervisor.ProcessConfig) string {
 	if config.StopWaitSecs > 0 {
 		sb.WriteString(fmt.Sprintf("stopwaitsecs=%d\n", config.StopWaitSecs))
 	}
+	for _, key := range supervisor.SortedKeys(config.Extra) {
+		sb.WriteString(fmt.Sprintf("%s=%s\n", key, config.Extra[key]))
+	}
 
 	return sb.String()
 }
@@ -220,9 +289,10 @@ func generateConfigText(config *supervisor.ProcessConfig) string {
 // parseConfigText parses config text into ProcessConfig
 func parseConfigText(text string) (*supervisor.ProcessConfig, error) {
 	config := &supervisor.ProcessConfig{
-		Environment: make(map[string]string),
-		Autostart:   false,
-		Autorestart: false,
+		Environment:       make(map[string]string),
+		Extra:             make(map[string]string),
+		Autostart:         true, // supervisord defaults
+		RestartUnexpected: true,
 	}
 
 	scanner := bufio.NewScanner(strings.NewReader(text))
@@ -245,9 +315,9 @@ func parseConfigText(text string) (*supervisor.ProcessConfig, error) {
 			continue
 		}
 
-		// Check if we're leaving a program section
+		// Further sections would be lost when saving a single program
 		if inProgramSection && strings.HasPrefix(line, "[") {
-			break
+			return nil, fmt.Errorf("only one [program:] section can be edited here, use E in the list to edit the whole file")
 		}
 
 		// Parse program configuration
@@ -287,7 +357,7 @@ func parseConfigLine(key, value string, config *supervisor.ProcessConfig) {
 	case "autostart":
 		config.Autostart = strings.ToLower(value) == "true"
 	case "autorestart":
-		config.Autorestart = strings.ToLower(value) == "true"
+		config.SetAutorestart(value)
 	case "startsecs":
 		if i, err := strconv.Atoi(value); err == nil {
 			config.StartSecs = i
@@ -324,6 +394,8 @@ func parseConfigLine(key, value string, config *supervisor.ProcessConfig) {
 		if i, err := strconv.Atoi(value); err == nil {
 			config.StopWaitSecs = i
 		}
+	default:
+		config.Extra[key] = value
 	}
 }
 
@@ -370,8 +442,8 @@ func formatBytes(bytes int64) string {
 
 func formatEnvironment(env map[string]string) string {
 	var pairs []string
-	for k, v := range env {
-		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	for _, k := range supervisor.SortedKeys(env) {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, env[k]))
 	}
 	return strings.Join(pairs, ",")
 }
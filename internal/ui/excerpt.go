@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// selectionMarkStyle marks the selected lines in the log viewer
+var selectionMarkStyle = lipgloss.NewStyle().Foreground(selectColor).Bold(true)
+
+// startSelection starts selecting lines at the current match, or at the
+// last line on the page if there is no match on it
+func (m *LogViewerModel) startSelection() {
+	if len(m.lines) == 0 {
+		m.notice = "Nothing to select"
+		return
+	}
+	line := m.hit
+	if line < m.offset || line >= m.offset+m.pageHeight() {
+		line = min(len(m.lines), m.offset+m.pageHeight()) - 1
+	}
+	m.selecting = true
+	m.selAnchor = line
+	m.selEnd = line
+	m.follow = false
+}
+
+// selection returns the first and last selected line
+func (m *LogViewerModel) selection() (int, int) {
+	first, last := min(m.selAnchor, m.selEnd), max(m.selAnchor, m.selEnd)
+	return max(0, first), min(last, len(m.lines)-1)
+}
+
+// moveSelection moves the end of the selection, scrolling to keep it visible
+func (m *LogViewerModel) moveSelection(delta int) {
+	m.selEnd = max(0, min(m.selEnd+delta, len(m.lines)-1))
+	if m.selEnd < m.offset {
+		m.offset = m.selEnd
+	} else if m.selEnd >= m.offset+m.pageHeight() {
+		m.offset = min(m.selEnd-m.pageHeight()+1, m.maxOffset())
+	}
+}
+
+// updateSelection handles keys while lines are selected
+func (m *LogViewerModel) updateSelection(msg tea.KeyMsg) (*LogViewerModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "v":
+		m.selecting = false
+	case "j", "down":
+		m.moveSelection(1)
+	case "k", "up":
+		m.moveSelection(-1)
+	case "ctrl+d":
+		m.moveSelection(m.pageHeight() / 2)
+	case "ctrl+u":
+		m.moveSelection(-m.pageHeight() / 2)
+	case "pgdown", "ctrl+f", " ":
+		m.moveSelection(m.pageHeight())
+	case "pgup", "ctrl+b":
+		m.moveSelection(-m.pageHeight())
+	case "g", "home":
+		m.moveSelection(-len(m.lines))
+	case "G", "end":
+		m.moveSelection(len(m.lines))
+	case "H":
+		m.excerptHeader = !m.excerptHeader
+	case "y":
+		m.copySelection()
+	case "w":
+		m.inputMode = "w"
+		m.input.SetValue(m.defaultExcerptPath())
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	}
+	return m, nil
+}
+
+// excerpt returns the selected lines without colors, preceded by the header if enabled
+func (m *LogViewerModel) excerpt() (string, int) {
+	first, last := m.selection()
+	var sb strings.Builder
+	if m.excerptHeader {
+		sb.WriteString(m.excerptHeaderText(first, last))
+	}
+	for _, line := range m.lines[first : last+1] {
+		sb.WriteString(plainText(line))
+		sb.WriteString("\n")
+	}
+	return sb.String(), last - first + 1
+}
+
+// excerptHeaderText describes where an excerpt comes from: program, host,
+// log file, time range and the program's config
+func (m *LogViewerModel) excerptHeaderText(first, last int) string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Program: %s (%s)", m.process.Name, m.streamLabel()))
+	if host, err := os.Hostname(); err == nil {
+		lines = append(lines, "Host: "+host)
+	}
+	lines = append(lines, "Log: "+m.Path())
+	if m.filter != nil {
+		lines = append(lines, "Filter: "+m.filterText)
+	}
+	if m.timeText != "" {
+		lines = append(lines, "Time range: "+m.timeText)
+	}
+
+	// Time range from the first and last lines with a timestamp
+	var from, to time.Time
+	for i := first; i <= last && from.IsZero(); i++ {
+		from, _ = lineTime(m.lines[i])
+	}
+	for i := last; i >= first && to.IsZero(); i-- {
+		to, _ = lineTime(m.lines[i])
+	}
+	if !from.IsZero() {
+		lines = append(lines, fmt.Sprintf("Time: %s .. %s", from.Format(time.RFC3339), to.Format(time.RFC3339)))
+	}
+	lines = append(lines, fmt.Sprintf("Lines: %d", last-first+1))
+	lines = append(lines, "Saved: "+time.Now().Format(time.RFC3339))
+
+	if m.process.Config != nil {
+		lines = append(lines, "Config:")
+		for _, line := range strings.Split(strings.TrimSpace(generateConfigText(m.process.Config)), "\n") {
+			// The environment often holds secrets
+			if strings.HasPrefix(line, "environment=") {
+				line = "environment=<omitted>"
+			}
+			lines = append(lines, "  "+line)
+		}
+	}
+
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString("# " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// copySelection copies the selected lines to the clipboard with an OSC 52
+// escape sequence, which terminals honor over SSH as well
+func (m *LogViewerModel) copySelection() {
+	text, count := m.excerpt()
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	// stderr is the terminal too, and bubbletea doesn't write to it
+	if _, err := seq.WriteTo(os.Stderr); err != nil {
+		m.notice = fmt.Sprintf("Failed to copy: %v", err)
+		return
+	}
+	m.selecting = false
+	m.notice = fmt.Sprintf("Copied %d lines to the clipboard", count)
+}
+
+// defaultExcerptPath suggests a file name for saving the selection
+func (m *LogViewerModel) defaultExcerptPath() string {
+	name := strings.ReplaceAll(m.process.Name, ":", "-")
+	return fmt.Sprintf("%s-%s.log", name, time.Now().Format("20060102-150405"))
+}
+
+// saveSelection writes the selected lines to a file
+func (m *LogViewerModel) saveSelection(path string) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return
+	}
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	text, count := m.excerpt()
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		m.notice = fmt.Sprintf("Failed to save: %v", err)
+		return
+	}
+	m.selecting = false
+	m.notice = fmt.Sprintf("Saved %d lines to %s", count, path)
+}
+
+// selectionStatus describes the selection and its keys
+func (m *LogViewerModel) selectionStatus() string {
+	first, last := m.selection()
+	header := "off"
+	if m.excerptHeader {
+		header = "on"
+	}
+	return fmt.Sprintf("selected %d lines | y: copy | w: save to file | H: header (%s) | Esc: cancel", last-first+1, header)
+}
@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+// HistoryModel represents the config history view for a program
+type HistoryModel struct {
+	program     string // Empty means history of all programs
+	entries     []*supervisor.HistoryEntry
+	selected    int
+	showContent bool // Show the full version instead of the diff
+	scroll      int  // Scroll offset of the preview pane
+	width       int
+	height      int
+	errorMsg    string
+}
+
+// NewHistoryModel creates a new history model
+func NewHistoryModel() *HistoryModel {
+	return &HistoryModel{}
+}
+
+// Load loads history entries for a program (empty for all programs)
+func (m *HistoryModel) Load(program string) {
+	m.program = program
+	m.selected = 0
+	m.scroll = 0
+	m.showContent = false
+	m.errorMsg = ""
+
+	entries, err := supervisor.LoadHistory(program)
+	if err != nil {
+		m.errorMsg = err.Error()
+	}
+	m.entries = entries
+}
+
+// SetSize sets the size of the history view
+func (m *HistoryModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clampScroll()
+}
+
+// GetSelected returns the currently selected history entry
+func (m *HistoryModel) GetSelected() *supervisor.HistoryEntry {
+	if m.selected < 0 || m.selected >= len(m.entries) {
+		return nil
+	}
+	return m.entries[m.selected]
+}
+
+// Update handles updates to the history model
+func (m *HistoryModel) Update(msg tea.Msg) (*HistoryModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "j", "down":
+			if m.selected < len(m.entries)-1 {
+				m.selected++
+				m.scroll = 0
+			}
+		case "k", "up":
+			if m.selected > 0 {
+				m.selected--
+				m.scroll = 0
+			}
+		case "enter", "tab":
+			m.showContent = !m.showContent
+			m.scroll = 0
+		case "ctrl+d", "pgdown":
+			m.scroll += m.previewHeight() / 2
+		case "ctrl+u", "pgup":
+			m.scroll = max(0, m.scroll-m.previewHeight()/2)
+		}
+		m.clampScroll()
+	}
+	return m, nil
+}
+
+// previewText returns the lines of the preview of the selected entry
+func (m *HistoryModel) previewText() []string {
+	entry := m.GetSelected()
+	if entry == nil {
+		return nil
+	}
+	text := entry.Diff
+	if m.showContent {
+		text = entry.Content()
+		if text == "" {
+			return nil
+		}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// clampScroll keeps the preview scroll offset within the preview text
+func (m *HistoryModel) clampScroll() {
+	m.scroll = max(0, min(m.scroll, len(m.previewText())-m.previewHeight()))
+}
+
+// listHeight returns the number of entry rows shown at the top of the view
+func (m *HistoryModel) listHeight() int {
+	return max(3, min(len(m.entries), (m.height-8)/3))
+}
+
+// previewHeight returns the number of lines available for the preview pane
+func (m *HistoryModel) previewHeight() int {
+	return max(3, m.height-8-m.listHeight()-3)
+}
+
+// View renders the history view
+func (m *HistoryModel) View() string {
+	title := "Config History"
+	if m.program != "" {
+		title = fmt.Sprintf("Config History: %s", m.program)
+	}
+
+	var lines []string
+	lines = append(lines, titleStyle.Render(title))
+
+	if m.errorMsg != "" {
+		lines = append(lines, errorStyle.Render("Error: "+m.errorMsg))
+	}
+
+	if len(m.entries) == 0 {
+		lines = append(lines, valueStyle.Foreground(subtleColor).Render("No recorded changes"))
+		lines = append(lines, "", helpStyle.Render("Esc: back"))
+		return detailPanelStyle.Width(m.width).Height(m.height).Render(strings.Join(lines, "\n"))
+	}
+
+	// Entry list, scrolled to keep the selection visible
+	listHeight := m.listHeight()
+	start := max(0, min(m.selected-listHeight/2, len(m.entries)-listHeight))
+	end := min(len(m.entries), start+listHeight)
+	for i := start; i < end; i++ {
+		entry := m.entries[i]
+		row := fmt.Sprintf("%s  %-7s  %-10s  %s",
+			entry.Time.Format("2006-01-02 15:04:05"),
+			entry.Action,
+			entry.User,
+			entry.Path,
+		)
+		row = truncateLine(row, m.width-8)
+		if i == m.selected {
+			lines = append(lines, listItemSelectedStyle.Render("▶ "+row))
+		} else {
+			lines = append(lines, listItemStyle.Render("  "+row))
+		}
+	}
+
+	// Preview of the selected entry
+	lines = append(lines, "")
+	if m.showContent {
+		lines = append(lines, titleStyle.Render("Version"))
+	} else {
+		lines = append(lines, titleStyle.Render("Diff"))
+	}
+
+	var preview []string
+	text := m.previewText()
+	for _, line := range text[min(m.scroll, len(text)):min(len(text), m.scroll+m.previewHeight())] {
+		line = truncateLine(line, m.width-6)
+		if m.showContent {
+			preview = append(preview, valueStyle.Render(line))
+		} else {
+			preview = append(preview, renderDiffLine(line))
+		}
+	}
+	if m.showContent && len(text) == 0 {
+		preview = []string{valueStyle.Foreground(subtleColor).Render("(empty)")}
+	}
+	lines = append(lines, preview...)
+
+	lines = append(lines, "", helpStyle.Render("j/k: select | Enter: diff/version | Ctrl+D/U: scroll | r: restore | Esc: back"))
+
+	return detailPanelStyle.Width(m.width).Height(m.height).Render(strings.Join(lines, "\n"))
+}
+
+// renderDiffLine colors a single line of a unified diff
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return labelStyle.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return valueStyle.Foreground(accentColor).Render(line)
+	case strings.HasPrefix(line, "+"):
+		return valueStyle.Foreground(successColor).Render(line)
+	case strings.HasPrefix(line, "-"):
+		return valueStyle.Foreground(errorColor).Render(line)
+	}
+	return valueStyle.Render(line)
+}
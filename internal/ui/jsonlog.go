@@ -0,0 +1,296 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Well-known keys of structured loggers (zap, logrus, slog, pino, bunyan, structlog, ...)
+var (
+	jsonTimeKeys    = []string{"time", "ts", "timestamp", "@timestamp", "t", "datetime"}
+	jsonLevelKeys   = []string{"level", "lvl", "severity", "@level", "levelname", "log.level"}
+	jsonMessageKeys = []string{"msg", "message", "@message", "event"}
+)
+
+// pinoLevels names the numeric levels of pino and bunyan
+var pinoLevels = map[string]string{
+	"10": "trace", "20": "debug", "30": "info", "40": "warn", "50": "error", "60": "fatal",
+}
+
+// jsonDimStyle renders the remaining fields of a JSON line
+var jsonDimStyle = lipgloss.NewStyle().Foreground(subtleColor)
+
+// jsonField is a top-level field of a JSON log line, in the order it was written
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+// jsonEntry is a log line holding a JSON object
+type jsonEntry struct {
+	fields  []jsonField
+	time    string
+	level   string
+	message string
+}
+
+// parseJSONLine parses a line holding a single JSON object
+func parseJSONLine(line string) (*jsonEntry, bool) {
+	line = strings.TrimSpace(plainText(line))
+	if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+
+	entry := &jsonEntry{}
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, false
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, false
+		}
+		entry.fields = append(entry.fields, jsonField{key: key, value: value})
+	}
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('}') {
+		return nil, false
+	}
+
+	entry.time = entry.take(jsonTimeKeys)
+	entry.level = entry.take(jsonLevelKeys)
+	if name, ok := pinoLevels[entry.level]; ok {
+		entry.level = name
+	}
+	entry.message = entry.take(jsonMessageKeys)
+	return entry, true
+}
+
+// take removes the first field with one of keys and returns its value as text
+func (e *jsonEntry) take(keys []string) string {
+	for _, key := range keys {
+		for i, f := range e.fields {
+			if f.key == key {
+				e.fields = append(e.fields[:i:i], e.fields[i+1:]...)
+				return jsonText(f.value)
+			}
+		}
+	}
+	return ""
+}
+
+// Time returns the entry's timestamp, either a formatted time or seconds/milliseconds since the epoch
+func (e *jsonEntry) Time() (time.Time, bool) {
+	if e.time == "" {
+		return time.Time{}, false
+	}
+	if epoch, err := strconv.ParseFloat(e.time, 64); err == nil {
+		// Milliseconds since the epoch are too large to be seconds before year 5000
+		if epoch > 1e11 {
+			epoch /= 1000
+		}
+		sec := int64(epoch)
+		return time.Unix(sec, int64((epoch-float64(sec))*1e9)), true
+	}
+	return parseTimestamp(e.time)
+}
+
+// jsonText returns a JSON value as text: strings unquoted, everything else compact
+func jsonText(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, value); err != nil {
+		return string(value)
+	}
+	return b.String()
+}
+
+// formatJSONLine renders a JSON line as time, level and message followed by the other fields
+func formatJSONLine(entry *jsonEntry) string {
+	var parts []string
+	if entry.time != "" {
+		// Seconds or milliseconds since the epoch are shown as a time
+		shown := entry.time
+		if _, err := strconv.ParseFloat(shown, 64); err == nil {
+			if t, ok := entry.Time(); ok {
+				shown = t.Format("2006-01-02 15:04:05.000")
+			}
+		}
+		parts = append(parts, jsonDimStyle.Render(shown))
+	}
+	if entry.level != "" {
+		parts = append(parts, levelStyle(entry.level).Render(fmt.Sprintf("%-5s", strings.ToUpper(entry.level))))
+	}
+	if entry.message != "" {
+		parts = append(parts, valueStyle.Render(entry.message))
+	}
+
+	var rest []string
+	for _, f := range entry.fields {
+		value := jsonText(f.value)
+		// Strings with spaces are quoted, objects and arrays are shown compact
+		if f.value[0] == '"' && strings.ContainsAny(value, " \t\"") {
+			value = strconv.Quote(value)
+		}
+		rest = append(rest, f.key+"="+value)
+	}
+	if len(rest) > 0 {
+		parts = append(parts, jsonDimStyle.Render(strings.Join(rest, " ")))
+	}
+	return strings.Join(parts, " ")
+}
+
+// indentJSONLine returns the lines of a JSON log line's object, indented
+func indentJSONLine(line string) ([]string, bool) {
+	text := strings.TrimSpace(plainText(line))
+	var b bytes.Buffer
+	if !strings.HasPrefix(text, "{") || json.Indent(&b, []byte(text), "", "  ") != nil {
+		return nil, false
+	}
+	return strings.Split(b.String(), "\n"), true
+}
+
+// renderLogText renders a log line truncated to width, as fields if it holds
+// a JSON object and pretty is set
+func renderLogText(line string, width int, style lipgloss.Style, pretty bool) string {
+	if pretty {
+		if entry, ok := parseJSONLine(line); ok {
+			return renderLogLine(truncateLine(formatJSONLine(entry), width), style)
+		}
+	}
+	return renderLogLine(truncateLine(line, width), style)
+}
+
+// fieldFilterRe matches a filter expression made of key=value and key!=value terms
+var fieldFilterRe = regexp.MustCompile(`^\s*[\w@.-]+!?=\S*(\s+[\w@.-]+!?=\S*)*\s*$`)
+
+// fieldFilter is a term of a filter expression like `level=error service=billing`
+type fieldFilter struct {
+	key    string
+	value  string
+	negate bool
+}
+
+// fieldFilters matches JSON lines whose fields satisfy all terms
+type fieldFilters []fieldFilter
+
+// parseFieldFilters parses a filter expression, returning false if text isn't one
+func parseFieldFilters(text string) (fieldFilters, bool) {
+	if !fieldFilterRe.MatchString(text) {
+		return nil, false
+	}
+	var filters fieldFilters
+	for _, term := range strings.Fields(text) {
+		key, value, _ := strings.Cut(term, "=")
+		negate := strings.HasSuffix(key, "!")
+		filters = append(filters, fieldFilter{key: strings.TrimSuffix(key, "!"), value: value, negate: negate})
+	}
+	return filters, true
+}
+
+// MatchString returns true if line is a JSON object matching all terms
+// Values are compared case-insensitively; dotted keys look into nested objects.
+func (f fieldFilters) MatchString(line string) bool {
+	text := strings.TrimSpace(line)
+	if !strings.HasPrefix(text, "{") {
+		return false
+	}
+	var object map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if err := decoder.Decode(&object); err != nil {
+		return false
+	}
+
+	for _, term := range f {
+		value, ok := lookupField(object, term.key)
+		equal := ok && strings.EqualFold(value, term.value)
+		if equal == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupField returns a field of object as text, following dots into nested objects
+// A key containing dots itself (like "log.level") is tried first.
+func lookupField(object map[string]interface{}, key string) (string, bool) {
+	value, ok := object[key]
+	if !ok {
+		head, rest, found := strings.Cut(key, ".")
+		nested, isObject := object[head].(map[string]interface{})
+		if !found || !isObject {
+			return "", false
+		}
+		return lookupField(nested, rest)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "null", true
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw), true
+	}
+}
+
+// expandLine shows the full JSON object of the current match, or of the
+// last JSON line on the page if there is no match on it
+func (m *LogViewerModel) expandLine() {
+	idx := m.hit
+	if idx < m.offset || idx >= m.offset+m.pageHeight() {
+		idx = -1
+		for i := min(len(m.lines), m.offset+m.pageHeight()) - 1; i >= m.offset; i-- {
+			if _, ok := parseJSONLine(m.lines[i]); ok {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		m.notice = "No JSON line on this page"
+		return
+	}
+
+	expanded, ok := indentJSONLine(m.lines[idx])
+	if !ok {
+		m.notice = "The line doesn't hold a JSON object"
+		return
+	}
+	m.expanded = expanded
+	m.hit = idx
+	m.panel = panelJSON
+	m.cursor = 0
+}
+
+// jsonPanelView renders the expanded JSON object
+func (m *LogViewerModel) jsonPanelView() []string {
+	lines := []string{labelStyle.Render(fmt.Sprintf("Line %d of %d", m.hit+1, len(m.lines))), ""}
+	end := min(len(m.expanded), m.cursor+m.pageHeight())
+	for _, line := range m.expanded[m.cursor:end] {
+		lines = append(lines, valueStyle.Render(truncateLine(line, m.width-8)))
+	}
+	return lines
+}
@@ -0,0 +1,286 @@
+package ui
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+// Log levels lines are classified into
+const (
+	levelError = "error"
+	levelWarn  = "warn"
+	levelInfo  = "info"
+	levelDebug = "debug"
+)
+
+// errorWindow is how far back errors are counted for the process list
+var errorWindow = 5 * time.Minute
+
+// errorCountLines is how many lines of each log are kept for counting errors
+const errorCountLines = 2000
+
+// SetErrorWindow sets how far back errors are counted for the process list
+func SetErrorWindow(window time.Duration) {
+	if window > 0 {
+		errorWindow = window
+	}
+}
+
+var (
+	// Level words near the start of a line: "ERROR", "[error]", "<warn>", "level=error"
+	levelWordRe = regexp.MustCompile(`(?:^|[\s\[(<|:=])(?i:(FATAL|CRITICAL|CRIT|EMERG(?:ENCY)?|ALERT|PANIC|SEVERE|ERROR|ERR|WARNING|WARN|NOTICE|INFO|DEBUG|TRACE))(?:$|[\s\])>|:,])`)
+	// glog/klog prefix: E0102 15:04:05.123456
+	glogRe = regexp.MustCompile(`^([EWIF])\d{4} \d{2}:\d{2}:\d{2}`)
+	// Exception lines without a level: Java's `Exception in thread "main" ...`,
+	// `java.lang.IllegalStateException: ...` or Python's `ValueError: ...`
+	exceptionRe = regexp.MustCompile(`^(?:Exception in thread "|[A-Za-z_][\w.$]*(?:Exception|Error)(?::|$))`)
+	// Go function lines of a panic stack: main.main() or net/http.(*conn).serve(0xc000)
+	goFrameRe = regexp.MustCompile(`^[\w./*()-]+\(.*\)$`)
+)
+
+// levelPrefixWidth is how far into a line level words are looked for,
+// so words in the message itself aren't taken for the level
+const levelPrefixWidth = 60
+
+// normalizeLevel maps the level names of the various loggers onto the four levels
+func normalizeLevel(name string) string {
+	switch strings.ToLower(name) {
+	case "fatal", "critical", "crit", "emerg", "emergency", "alert", "panic", "severe", "error", "err", "e", "f":
+		return levelError
+	case "warning", "warn", "w":
+		return levelWarn
+	case "notice", "info", "i":
+		return levelInfo
+	case "debug", "trace":
+		return levelDebug
+	}
+	return ""
+}
+
+// lineLevel detects the level of a single line, empty if it has none
+func lineLevel(line string) string {
+	if entry, ok := parseJSONLine(line); ok {
+		return normalizeLevel(entry.level)
+	}
+
+	text := plainText(line)
+	if match := glogRe.FindStringSubmatch(text); match != nil {
+		return normalizeLevel(match[1])
+	}
+	if len(text) > levelPrefixWidth {
+		text = text[:levelPrefixWidth]
+	}
+	if match := levelWordRe.FindStringSubmatch(text); match != nil {
+		return normalizeLevel(match[1])
+	}
+	if exceptionRe.MatchString(text) {
+		return levelError
+	}
+	return ""
+}
+
+// levelClassifier classifies the lines of a log in order, so that lines of a
+// Python traceback, Go panic or indented stack trace get the level of the error
+type levelClassifier struct {
+	trace string // "python" or "go" inside a traceback or panic
+	last  string // Level of the previous line
+}
+
+// classify returns the level of the next line, and whether the line continues
+// the entry before it (a stack trace line) rather than starting a new one
+func (c *levelClassifier) classify(line string) (string, bool) {
+	text := plainText(line)
+	indented := strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")
+
+	switch c.trace {
+	case "python":
+		// The traceback ends with the unindented exception line
+		if !indented {
+			c.trace = ""
+		}
+		return levelError, true
+	case "go":
+		if indented || text == "" || strings.HasPrefix(text, "goroutine ") ||
+			strings.HasPrefix(text, "created by ") || goFrameRe.MatchString(text) {
+			return levelError, true
+		}
+		c.trace = ""
+	}
+
+	switch {
+	case strings.HasPrefix(text, "Traceback (most recent call last)"):
+		// Logged exceptions put the traceback right after the error line
+		continued := c.last == levelError
+		c.trace = "python"
+		c.last = levelError
+		return levelError, continued
+	case strings.HasPrefix(text, "panic: ") || strings.HasPrefix(text, "fatal error: "):
+		c.trace = "go"
+		c.last = levelError
+		return levelError, false
+	}
+
+	level := lineLevel(line)
+	if level == "" && indented && (c.last == levelError || c.last == levelWarn) {
+		// Indented lines after an error, like Java's "\tat ...", belong to it
+		return c.last, true
+	}
+	c.last = level
+	return level, false
+}
+
+// classifyLines returns the level of each line
+func classifyLines(lines []string) []string {
+	var c levelClassifier
+	levels := make([]string, len(lines))
+	for i, line := range lines {
+		levels[i], _ = c.classify(line)
+	}
+	return levels
+}
+
+// problemLines returns the last n error and warning lines, including the stack trace lines that belong to them
+func problemLines(lines []string, n int) []string {
+	var c levelClassifier
+	var problems []string
+	for _, line := range lines {
+		if level, _ := c.classify(line); level == levelError || level == levelWarn {
+			problems = append(problems, line)
+		}
+	}
+	if len(problems) > n {
+		problems = problems[len(problems)-n:]
+	}
+	return problems
+}
+
+// levelStyle returns the style of a level badge
+func levelStyle(level string) lipgloss.Style {
+	switch normalizeLevel(level) {
+	case levelError:
+		return lipgloss.NewStyle().Foreground(errorColor).Bold(true)
+	case levelWarn:
+		return lipgloss.NewStyle().Foreground(warningColor).Bold(true)
+	case levelInfo:
+		return lipgloss.NewStyle().Foreground(successColor)
+	}
+	return lipgloss.NewStyle().Foreground(subtleColor)
+}
+
+// lineStyle returns the style of a log line of the given level
+func lineStyle(level string) lipgloss.Style {
+	switch level {
+	case levelError:
+		return valueStyle.Foreground(errorColor)
+	case levelWarn:
+		return valueStyle.Foreground(warningColor)
+	case levelDebug:
+		return valueStyle.Foreground(subtleColor)
+	}
+	return valueStyle
+}
+
+// errorLog tracks the recent errors written to a log file
+type errorLog struct {
+	reader     *tailReader
+	classifier levelClassifier
+	consumed   int
+	resets     int
+	times      []time.Time // When each error within the window was logged
+}
+
+// ErrorCounter counts error lines each process logged recently
+// Updates read the logs, so they run in a background command, one at a time.
+type ErrorCounter struct {
+	logs map[string]*errorLog
+}
+
+// NewErrorCounter creates a new error counter
+func NewErrorCounter() *ErrorCounter {
+	return &ErrorCounter{logs: make(map[string]*errorLog)}
+}
+
+// Update reads new lines of the processes' logs and returns the number of
+// errors each process logged within the error window
+func (c *ErrorCounter) Update(processes []*supervisor.Process) map[string]int {
+	counts := make(map[string]int)
+	since := time.Now().Add(-errorWindow)
+	used := make(map[string]bool)
+
+	for _, proc := range processes {
+		for _, path := range []string{proc.Logs.Stdout, proc.Logs.Stderr} {
+			if path == "" || used[path] {
+				continue
+			}
+			used[path] = true
+
+			log, ok := c.logs[path]
+			if !ok {
+				log = &errorLog{reader: newTailReader(path, errorCountLines)}
+				c.logs[path] = log
+			}
+			if count := log.update(since); count > 0 {
+				counts[proc.Name] += count
+			}
+		}
+	}
+
+	// Forget logs of removed programs
+	for path := range c.logs {
+		if !used[path] {
+			delete(c.logs, path)
+		}
+	}
+	return counts
+}
+
+// update reads new lines and returns the number of errors logged since then
+func (l *errorLog) update(since time.Time) int {
+	arrived := time.Now()
+	if info, err := os.Stat(l.reader.path); err == nil && !l.reader.loaded {
+		// Errors without a timestamp from the first read are at most as old as the file
+		arrived = info.ModTime()
+	}
+
+	if _, err := l.reader.Read(); err != nil {
+		l.times = nil
+		return 0
+	}
+	if l.reader.resets != l.resets {
+		l.resets = l.reader.resets
+		l.consumed = 0
+		l.classifier = levelClassifier{}
+		l.times = nil
+	}
+
+	lines := l.reader.lines
+	added := min(l.reader.total-l.consumed, len(lines))
+	for _, line := range lines[len(lines)-added:] {
+		// Stack trace lines are part of the error before them
+		level, continued := l.classifier.classify(line)
+		if level != levelError || continued {
+			continue
+		}
+		t, ok := lineTime(line)
+		if !ok {
+			t = arrived
+		}
+		l.times = append(l.times, t)
+	}
+	l.consumed = l.reader.total
+
+	// Drop errors that left the window
+	kept := l.times[:0]
+	for _, t := range l.times {
+		if !t.Before(since) {
+			kept = append(kept, t)
+		}
+	}
+	l.times = kept
+	return len(l.times)
+}
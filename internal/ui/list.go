@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,6 +12,10 @@ import (
 type ListModel struct {
 	processes  []*supervisor.Process
 	filtered   []*supervisor.Process
+	marked     map[string]bool // Processes marked for bulk actions
+	pending    supervisor.ConfigChanges
+	errors     map[string]int // Errors logged per process within the error window
+	alerts     map[string]int // Alerts raised per process since they were last looked at
 	selected   int
 	searchTerm string
 	width      int
@@ -22,6 +27,7 @@ func NewListModel(processes []*supervisor.Process) *ListModel {
 	return &ListModel{
 		processes: processes,
 		filtered:  processes,
+		marked:    make(map[string]bool),
 		selected:  0,
 	}
 }
@@ -55,6 +61,21 @@ func (m *ListModel) SetProcesses(processes []*supervisor.Process) {
 	m.ApplyFilter()
 }
 
+// SetPending sets the config changes that haven't been applied yet
+func (m *ListModel) SetPending(changes supervisor.ConfigChanges) {
+	m.pending = changes
+}
+
+// SetErrorCounts sets the number of errors each process logged recently
+func (m *ListModel) SetErrorCounts(counts map[string]int) {
+	m.errors = counts
+}
+
+// SetAlertCounts sets the number of alerts each process raised
+func (m *ListModel) SetAlertCounts(counts map[string]int) {
+	m.alerts = counts
+}
+
 // ApplyFilter applies the current search filter
 func (m *ListModel) ApplyFilter() {
 	if m.searchTerm == "" {
@@ -105,6 +126,52 @@ func (m *ListModel) SetSelected(index int) {
 	}
 }
 
+// ToggleMark marks or unmarks the selected process
+func (m *ListModel) ToggleMark() {
+	proc := m.GetSelected()
+	if proc == nil {
+		return
+	}
+	if m.marked[proc.Name] {
+		delete(m.marked, proc.Name)
+	} else {
+		m.marked[proc.Name] = true
+	}
+}
+
+// ToggleMarkAll marks all visible processes, or clears all marks if any are set
+func (m *ListModel) ToggleMarkAll() {
+	if len(m.marked) > 0 {
+		m.marked = make(map[string]bool)
+		return
+	}
+	for _, proc := range m.filtered {
+		m.marked[proc.Name] = true
+	}
+}
+
+// GetMarked returns the marked processes in list order
+func (m *ListModel) GetMarked() []*supervisor.Process {
+	var marked []*supervisor.Process
+	for _, proc := range m.processes {
+		if m.marked[proc.Name] {
+			marked = append(marked, proc)
+		}
+	}
+	return marked
+}
+
+// GetTargets returns the marked processes, or the selected one if none are marked
+func (m *ListModel) GetTargets() []*supervisor.Process {
+	if marked := m.GetMarked(); len(marked) > 0 {
+		return marked
+	}
+	if proc := m.GetSelected(); proc != nil {
+		return []*supervisor.Process{proc}
+	}
+	return nil
+}
+
 // GetSelectedIndex returns the currently selected index
 func (m *ListModel) GetSelectedIndex() int {
 	return m.selected
@@ -171,12 +238,31 @@ func (m *ListModel) View() string {
 	return listPanelStyle.Width(m.width).Height(m.height).Render(content)
 }
 
+// pendingBadges mark processes whose config on disk hasn't been applied
+var pendingBadges = map[string]string{
+	supervisor.ChangeAdded:   "+new",
+	supervisor.ChangeChanged: "~changed",
+	supervisor.ChangeRemoved: "-removed",
+}
+
 // formatEntry formats a single entry for display
 func (m *ListModel) formatEntry(proc *supervisor.Process, selected bool) string {
 	statusStyle := GetStatusStyle(proc.Status)
 	statusBadge := statusStyle.Render("[" + proc.Status + "]")
 
 	mainLine := proc.Name + " " + statusBadge
+	if kind := m.pending.For(proc.Name); kind != "" {
+		mainLine += " " + pendingStyle.Render(pendingBadges[kind])
+	}
+	if count := m.errors[proc.Name]; count > 0 {
+		mainLine += " " + errorStyle.Render(fmt.Sprintf("✖%d", count))
+	}
+	if count := m.alerts[proc.Name]; count > 0 {
+		mainLine += " " + warningStyle.Render(fmt.Sprintf("⚑%d", count))
+	}
+	if m.marked[proc.Name] {
+		mainLine = "● " + mainLine
+	}
 
 	if selected {
 		mainLine = "▶ " + mainLine
@@ -189,17 +275,3 @@ func (m *ListModel) formatEntry(proc *supervisor.Process, selected bool) string
 	}
 	return listItemStyle.Render(mainLine)
 }
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
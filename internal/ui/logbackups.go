@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Lists shown over the log in the viewer
+const (
+	panelBackups = "backups"
+	panelResults = "results"
+	panelJSON    = "json"
+)
+
+// backupsMsg carries the log files found for the backups list
+type backupsMsg struct {
+	path  string
+	files []logFile
+}
+
+// backupSearchMsg carries the result of a search across all backups
+type backupSearchMsg struct {
+	pattern   string
+	re        *regexp.Regexp
+	hits      []backupHit
+	truncated bool
+	err       error
+}
+
+// openBackups shows the list of rotated backups of the current log
+// Time ranges are read in the background, compressed files have to be decompressed.
+func (m *LogViewerModel) openBackups() tea.Cmd {
+	path := m.logPath()
+	if path == "" {
+		m.notice = "No logfile configured"
+		return nil
+	}
+	m.panel = panelBackups
+	m.files = nil
+	m.cursor = 0
+	m.loading = true
+	return func() tea.Msg {
+		return backupsMsg{path: path, files: findLogFiles(path)}
+	}
+}
+
+// searchBackups searches the current log and all of its backups in the background
+func (m *LogViewerModel) searchBackups(text string) tea.Cmd {
+	re := m.compile(text)
+	if re == nil {
+		return nil
+	}
+	files := m.files
+	m.panel = panelResults
+	m.hits = nil
+	m.cursor = 0
+	m.loading = true
+	return func() tea.Msg {
+		hits, truncated, err := searchLogFiles(files, re)
+		return backupSearchMsg{pattern: text, re: re, hits: hits, truncated: truncated, err: err}
+	}
+}
+
+// updatePanel handles keys while the backups list or search results are shown
+func (m *LogViewerModel) updatePanel(msg tea.KeyMsg) (*LogViewerModel, tea.Cmd) {
+	count := len(m.files)
+	switch m.panel {
+	case panelResults:
+		count = len(m.hits)
+	case panelJSON:
+		count = max(1, len(m.expanded)-m.pageHeight()+1)
+	}
+
+	switch msg.String() {
+	case "esc", "q", "b", "x":
+		// Results go back to the list they were searched from
+		if m.panel == panelResults && msg.String() == "esc" {
+			m.panel = panelBackups
+			m.cursor = 0
+		} else {
+			m.panel = ""
+		}
+	case "j", "down":
+		m.cursor = min(m.cursor+1, max(0, count-1))
+	case "k", "up":
+		m.cursor = max(0, m.cursor-1)
+	case "g", "home":
+		m.cursor = 0
+	case "G", "end":
+		m.cursor = max(0, count-1)
+	case "/":
+		if m.panel == panelBackups && !m.loading {
+			return m, m.startInput("*")
+		}
+	case "enter":
+		if m.cursor >= count || m.loading || m.panel == panelJSON {
+			return m, nil
+		}
+		if m.panel == panelBackups {
+			m.openFile(m.files[m.cursor].path)
+		} else {
+			m.openHit(m.hits[m.cursor])
+		}
+	}
+	return m, nil
+}
+
+// openFile shows a log file or one of its backups instead of the current log
+func (m *LogViewerModel) openFile(path string) {
+	m.panel = ""
+	m.file = ""
+	m.clearTimeRange()
+	if path != m.logPath() {
+		m.file = path
+	}
+	m.follow = true
+	m.hit = -1
+	m.Reload()
+}
+
+// openHit shows the file of a search result with the matching line selected
+func (m *LogViewerModel) openHit(hit backupHit) {
+	m.searchText = m.hitsPattern
+	m.search = m.hitsRe
+	m.openFile(hit.file)
+
+	// The viewer only keeps the last lines of a file: count matches from the end
+	seen := 0
+	for i := len(m.lines) - 1; i >= 0; i-- {
+		if !m.search.MatchString(plainText(m.lines[i])) {
+			continue
+		}
+		if seen == hit.fromEnd {
+			m.hit = i
+			m.follow = false
+			m.offset = max(0, min(i-m.pageHeight()/2, m.maxOffset()))
+			return
+		}
+		seen++
+	}
+	m.notice = fmt.Sprintf("Line %d is older than the last %d lines, press e to open the file", hit.line, viewerMaxLines)
+}
+
+// panelView renders the backups list or the search results
+func (m *LogViewerModel) panelView() []string {
+	if m.panel == panelJSON {
+		return m.jsonPanelView()
+	}
+
+	var lines []string
+	current := m.logPath()
+
+	if m.panel == panelBackups {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("Backups of %s", current)))
+		if m.inputMode == "*" {
+			lines = append(lines, labelStyle.Render("Search all:")+" "+m.input.View())
+		} else {
+			lines = append(lines, pendingStyle.Render(m.notice))
+		}
+		if m.loading {
+			return append(lines, valueStyle.Foreground(subtleColor).Render("Reading log files..."))
+		}
+		if len(m.files) == 0 {
+			return append(lines, valueStyle.Foreground(subtleColor).Render("No log files found"))
+		}
+
+		start := max(0, min(m.cursor-m.pageHeight()/2, len(m.files)-m.pageHeight()))
+		end := min(len(m.files), start+m.pageHeight())
+		for i := start; i < end; i++ {
+			f := m.files[i]
+			shown := f.path == m.Path()
+			entry := fmt.Sprintf("%-14s %8s  %s", f.label(current), formatSize(f.size), f.timeRange())
+			if shown {
+				entry += "  (shown)"
+			}
+			lines = append(lines, m.panelEntry(i, entry))
+		}
+		return lines
+	}
+
+	header := fmt.Sprintf("Matches of %q in all backups", m.hitsPattern)
+	switch {
+	case m.loading:
+	case m.hitsTruncated:
+		header += fmt.Sprintf(" (last %d)", maxBackupHits)
+	default:
+		header += fmt.Sprintf(" (%d)", len(m.hits))
+	}
+	lines = append(lines, labelStyle.Render(header))
+	lines = append(lines, pendingStyle.Render(m.notice))
+	if m.loading {
+		return append(lines, valueStyle.Foreground(subtleColor).Render("Searching..."))
+	}
+	if len(m.hits) == 0 {
+		return append(lines, valueStyle.Foreground(subtleColor).Render("No matches"))
+	}
+
+	start := max(0, min(m.cursor-m.pageHeight()/2, len(m.hits)-m.pageHeight()))
+	end := min(len(m.hits), start+m.pageHeight())
+	for i := start; i < end; i++ {
+		hit := m.hits[i]
+		prefix := fmt.Sprintf("%s:%d: ", m.files[hit.fileIndex].label(current), hit.line)
+		lines = append(lines, m.panelEntry(i, prefix+strings.ReplaceAll(plainText(hit.text), "\t", "    ")))
+	}
+	return lines
+}
+
+// panelEntry renders an entry of the panel, highlighting the cursor
+func (m *LogViewerModel) panelEntry(i int, text string) string {
+	text = truncateLine(text, m.width-8)
+	if i == m.cursor {
+		return listItemSelectedStyle.Render("▶ " + text)
+	}
+	return listItemStyle.Render("  " + text)
+}
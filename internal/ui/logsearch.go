@@ -0,0 +1,301 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// matchStyle highlights search matches in log lines
+var matchStyle = lipgloss.NewStyle().
+	Foreground(bgColor).
+	Background(warningColor)
+
+// lineMatcher matches log lines, a *regexp.Regexp or fieldFilters
+type lineMatcher interface {
+	MatchString(line string) bool
+}
+
+// Capturing returns true while a search or filter pattern is being typed,
+// the backups list is shown or lines are selected, so that all keys go to the viewer
+func (m *LogViewerModel) Capturing() bool {
+	return m.inputMode != "" || m.panel != "" || m.selecting
+}
+
+// Escape clears the active search and filter, then goes back from a backup
+// to the current log. It returns false if there was nothing to clear.
+func (m *LogViewerModel) Escape() bool {
+	if m.search == nil && m.filter == nil && m.timeText == "" {
+		if m.file == "" {
+			return false
+		}
+		m.openFile(m.logPath())
+		return true
+	}
+	m.search = nil
+	m.searchText = ""
+	m.filter = nil
+	m.filterText = ""
+	m.clearTimeRange()
+	m.hit = -1
+	m.applyFilter()
+	return true
+}
+
+// startInput starts typing a search ("/" or "?") or filter ("&") pattern
+func (m *LogViewerModel) startInput(mode string) tea.Cmd {
+	m.inputMode = mode
+	m.input.SetValue("")
+	switch mode {
+	case "&":
+		m.input.SetValue(m.filterText)
+	case "T":
+		m.input.SetValue(m.timeText)
+	}
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// updateInput handles keys while a pattern is typed
+func (m *LogViewerModel) updateInput(msg tea.KeyMsg) (*LogViewerModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.inputMode = ""
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		mode := m.inputMode
+		text := m.input.Value()
+		m.inputMode = ""
+		m.input.Blur()
+
+		if mode == "*" {
+			return m, m.searchBackups(text)
+		}
+		if mode == "w" {
+			m.saveSelection(text)
+			return m, nil
+		}
+		if mode == "t" {
+			m.jumpToTime(text)
+			return m, nil
+		}
+		if mode == "T" {
+			return m, m.setTimeRange(text)
+		}
+		if mode == "&" {
+			m.filterText = text
+			m.filter = m.compileFilter(text)
+			m.hit = -1
+			m.applyFilter()
+			return m, nil
+		}
+
+		// An empty pattern repeats the last search
+		if text != "" {
+			m.searchText = text
+			m.search = m.compile(text)
+		}
+		m.backward = mode == "?"
+		m.findNext(m.backward)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// compile turns a pattern into a regexp, honoring the case setting
+// Patterns that aren't valid regular expressions are matched literally
+func (m *LogViewerModel) compile(text string) *regexp.Regexp {
+	if text == "" {
+		return nil
+	}
+	prefix := "(?i)"
+	if m.matchCase {
+		prefix = ""
+	}
+	re, err := regexp.Compile(prefix + text)
+	if err != nil {
+		m.notice = "Invalid regular expression, matching literally"
+		re = regexp.MustCompile(prefix + regexp.QuoteMeta(text))
+	}
+	return re
+}
+
+// compileFilter turns a filter into a matcher: expressions like
+// `level=error service=billing` match JSON fields, anything else is a regular expression
+func (m *LogViewerModel) compileFilter(text string) lineMatcher {
+	if filters, ok := parseFieldFilters(text); ok {
+		return filters
+	}
+	if re := m.compile(text); re != nil {
+		return re
+	}
+	return nil
+}
+
+// toggleCase switches between case-sensitive and case-insensitive matching
+func (m *LogViewerModel) toggleCase() {
+	m.matchCase = !m.matchCase
+	m.search = m.compile(m.searchText)
+	m.filter = m.compileFilter(m.filterText)
+	m.applyFilter()
+	if m.matchCase {
+		m.notice = "Case-sensitive matching"
+	} else {
+		m.notice = "Case-insensitive matching"
+	}
+}
+
+// applyFilter updates the shown lines from all lines, the filter and the time range
+func (m *LogViewerModel) applyFilter() {
+	all, levels := m.all, m.levels
+	if m.rangeLines != nil {
+		all, levels = m.rangeLines, m.rangeLevels
+	}
+
+	if m.filter == nil && m.timeText == "" {
+		m.lines = all
+		m.shown = levels
+	} else {
+		var times []time.Time
+		if m.timeText != "" {
+			times = m.sourceTimes()
+		}
+		m.lines = nil
+		m.shown = nil
+		for i, line := range all {
+			if times != nil && !m.inTimeRange(times[i]) {
+				continue
+			}
+			if m.filter != nil && !m.filter.MatchString(plainText(line)) {
+				continue
+			}
+			m.lines = append(m.lines, line)
+			m.shown = append(m.shown, levels[i])
+		}
+	}
+
+	if m.hit >= len(m.lines) {
+		m.hit = -1
+	}
+	if m.follow {
+		m.offset = m.maxOffset()
+	} else {
+		m.offset = min(m.offset, m.maxOffset())
+	}
+}
+
+// findNext moves to the next match of the search, wrapping around the log
+func (m *LogViewerModel) findNext(backward bool) {
+	if m.search == nil || len(m.lines) == 0 {
+		return
+	}
+
+	// Start at the current match, or at the edge of the visible page
+	from := m.hit
+	if from < m.offset || from >= m.offset+m.pageHeight() {
+		from = m.offset - 1
+		if backward {
+			from = min(len(m.lines), m.offset+m.pageHeight())
+		}
+	}
+
+	step := 1
+	if backward {
+		step = -1
+	}
+	n := len(m.lines)
+	for i := 1; i <= n; i++ {
+		idx := ((from+step*i)%n + n) % n
+		if m.search.MatchString(plainText(m.lines[idx])) {
+			if (backward && idx > from) || (!backward && idx < from) {
+				m.notice = "Search wrapped around"
+			}
+			m.hit = idx
+			m.follow = false
+			m.offset = max(0, min(idx-m.pageHeight()/2, m.maxOffset()))
+			return
+		}
+	}
+	m.notice = fmt.Sprintf("Pattern not found: %s", m.searchText)
+}
+
+// matchCount returns the number of lines matching the search
+func (m *LogViewerModel) matchCount() int {
+	count := 0
+	for _, line := range m.lines {
+		if m.search.MatchString(plainText(line)) {
+			count++
+		}
+	}
+	return count
+}
+
+// searchStatus renders the pattern input, or the active search and filter
+func (m *LogViewerModel) searchStatus() string {
+	if m.inputMode != "" {
+		label := "Search:"
+		switch m.inputMode {
+		case "?":
+			label = "Search backward:"
+		case "&":
+			label = "Filter:"
+		case "w":
+			label = "Save to:"
+		case "t":
+			label = "Go to time:"
+		case "T":
+			label = "Time range:"
+		}
+		return labelStyle.Render(label) + " " + m.input.View()
+	}
+
+	var parts []string
+	if m.selecting {
+		parts = append(parts, m.selectionStatus())
+	}
+	if m.search != nil {
+		parts = append(parts, fmt.Sprintf("search: %s (%d lines)", m.searchText, m.matchCount()))
+	}
+	if m.filter != nil {
+		parts = append(parts, fmt.Sprintf("filter: %s (%d of %d lines)", m.filterText, len(m.lines), len(m.all)))
+	}
+	if m.timeText != "" {
+		parts = append(parts, m.timeRangeStatus())
+	}
+	if len(parts) > 0 && m.matchCase {
+		parts = append(parts, "case-sensitive")
+	}
+	if m.notice != "" {
+		parts = append(parts, m.notice)
+	}
+
+	return pendingStyle.Render(truncateLine(strings.Join(parts, " | "), m.width-6))
+}
+
+// highlightMatches renders line with all matches of re highlighted
+// Lines with matches lose their own colors, so the highlight stays visible.
+func highlightMatches(line string, re *regexp.Regexp, style lipgloss.Style) string {
+	if re == nil || !re.MatchString(plainText(line)) {
+		return renderLogLine(line, style)
+	}
+	line = plainText(line)
+
+	var out string
+	last := 0
+	for _, loc := range re.FindAllStringIndex(line, -1) {
+		if loc[0] == loc[1] {
+			continue // Skip empty matches
+		}
+		out += style.Render(line[last:loc[0]]) + matchStyle.Render(line[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	return out + style.Render(line[last:])
+}
@@ -0,0 +1,430 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+// Log streams of a process
+const (
+	streamStdout = "stdout"
+	streamStderr = "stderr"
+)
+
+const viewerMaxLines = 10000 // Lines kept in the log viewer
+
+// LogViewerModel represents the full-screen log viewer
+type LogViewerModel struct {
+	process  *supervisor.Process
+	stream   string // streamStdout or streamStderr
+	reader   *tailReader
+	all      []string // All lines read from the log
+	levels   []string // Level of each line in all
+	lines    []string // Lines shown, after the filter
+	shown    []string // Level of each line in lines
+	offset   int      // Index of the first visible line
+	follow   bool     // Keep the view at the end as new lines arrive
+	width    int
+	height   int
+	errorMsg string
+
+	// Search and filter
+	input      textinput.Model
+	inputMode  string // "/", "?", "&", "*" or "w" while a pattern or path is typed, empty otherwise
+	search     *regexp.Regexp
+	searchText string
+	backward   bool        // The last search was started with ?
+	filter     lineMatcher // Regular expression or JSON field filter
+	filterText string
+	matchCase  bool
+	hit        int // Index in lines of the current match, -1 if none
+	notice     string
+	rawJSON    bool // Show JSON lines as written instead of as fields
+
+	// Rotated backups
+	file          string // Backup shown instead of the current log, empty for the current log
+	files         []logFile
+	panel         string // panelBackups, panelResults or panelJSON while a list is shown over the log
+	cursor        int
+	expanded      []string // Indented JSON object of the expanded line
+	loading       bool
+	hits          []backupHit
+	hitsPattern   string
+	hitsRe        *regexp.Regexp
+	hitsTruncated bool
+
+	// Line selection for excerpts
+	selecting     bool
+	selAnchor     int // Line the selection started at
+	selEnd        int // Line the selection was moved to
+	excerptHeader bool
+
+	// Time range
+	times        []time.Time // Time of each line in all, computed while a time range is used
+	timeText     string      // The range as typed, empty if none
+	timeFrom     time.Time   // Zero for an open start
+	timeTo       time.Time   // Exclusive, zero for an open end
+	rangeLines   []string    // Lines of the range from the log and its backups, shown instead of all
+	rangeLevels  []string
+	rangeTimes   []time.Time
+	rangeLoading bool
+
+	// supervisord's own log
+	daemon      bool           // Showing supervisord's log rather than a program's
+	mention     *regexp.Regexp // Marks lines mentioning the selected program
+	mentionName string
+}
+
+// NewLogViewerModel creates a new log viewer model
+func NewLogViewerModel() *LogViewerModel {
+	input := textinput.New()
+	input.Prompt = ""
+	return &LogViewerModel{stream: streamStdout, input: input, hit: -1}
+}
+
+// Open shows a log stream of a process, following new lines
+func (m *LogViewerModel) Open(process *supervisor.Process, stream string) {
+	m.process = process
+	m.stream = stream
+	m.daemon = false
+	m.mention = nil
+	m.clearTimeRange()
+	m.file = ""
+	m.panel = ""
+	m.follow = true
+	m.Reload()
+}
+
+// Path returns the log file currently shown
+func (m *LogViewerModel) Path() string {
+	if m.file != "" {
+		return m.file
+	}
+	return m.logPath()
+}
+
+// logPath returns the log file the process writes the stream to
+// With redirect_stderr both streams are in the stdout log.
+func (m *LogViewerModel) logPath() string {
+	if m.process == nil {
+		return ""
+	}
+	logs := m.process.Logs
+	if m.stream == streamStderr && !logs.RedirectStderr {
+		return logs.Stderr
+	}
+	return logs.Stdout
+}
+
+// streamLabel names the shown stream in the title
+func (m *LogViewerModel) streamLabel() string {
+	if m.daemon {
+		return "daemon log"
+	}
+	if m.process != nil && m.process.Logs.RedirectStderr {
+		return "stdout+stderr"
+	}
+	return m.stream
+}
+
+// missingReason explains why the stream has no log file
+func (m *LogViewerModel) missingReason() string {
+	logs := m.process.Logs
+	note := logs.StdoutNote
+	if m.stream == streamStderr && !logs.RedirectStderr {
+		note = logs.StderrNote
+	}
+	if note != "" {
+		return note
+	}
+	return fmt.Sprintf("No %s logfile configured", m.stream)
+}
+
+// Reload re-reads the log file, keeping the view at the end when following
+func (m *LogViewerModel) Reload() {
+	m.errorMsg = ""
+	path := m.Path()
+	switch {
+	case m.process == nil:
+		m.all = nil
+		m.lines = nil
+		return
+	case m.process.Config == nil && path == "":
+		m.all = nil
+		m.lines = nil
+		m.errorMsg = "Config not loaded for this process"
+		return
+	case path == "":
+		m.all = nil
+		m.lines = nil
+		m.errorMsg = m.missingReason()
+		return
+	}
+
+	// Only what was appended since the last reload is read
+	if m.reader == nil || m.reader.path != path {
+		m.reader = newTailReader(path, viewerMaxLines)
+	}
+	changed, err := m.reader.Read()
+	// While a log is rotated it's briefly missing, the new file continues it
+	if err != nil && !(os.IsNotExist(err) && m.reader.loaded) {
+		m.errorMsg = err.Error()
+		m.reader = nil
+	}
+	m.all = nil
+	if m.reader != nil {
+		m.all = m.reader.Lines()
+	}
+	// Levels depend on the lines before, e.g. inside a stack trace
+	if changed || len(m.levels) != len(m.all) {
+		m.levels = classifyLines(m.all)
+		m.times = nil
+	}
+	m.applyFilter()
+
+	if m.follow {
+		m.offset = m.maxOffset()
+	} else {
+		m.offset = min(m.offset, m.maxOffset())
+	}
+}
+
+// SetSize sets the size of the log viewer
+func (m *LogViewerModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	if m.follow {
+		m.offset = m.maxOffset()
+	}
+}
+
+// pageHeight returns the number of log lines visible at once
+func (m *LogViewerModel) pageHeight() int {
+	return max(3, m.height-9)
+}
+
+// maxOffset returns the offset that shows the last page
+func (m *LogViewerModel) maxOffset() int {
+	return max(0, len(m.lines)-m.pageHeight())
+}
+
+// scroll moves the view by delta lines; scrolling up stops following
+func (m *LogViewerModel) scroll(delta int) {
+	m.offset = max(0, min(m.offset+delta, m.maxOffset()))
+	if delta < 0 {
+		m.follow = false
+	}
+}
+
+// Update handles updates to the log viewer
+func (m *LogViewerModel) Update(msg tea.Msg) (*LogViewerModel, tea.Cmd) {
+	var keyMsg tea.KeyMsg
+	switch msg := msg.(type) {
+	case backupsMsg:
+		if m.panel == panelBackups && msg.path == m.logPath() {
+			m.files = msg.files
+			m.loading = false
+			for i, f := range m.files {
+				if f.path == m.Path() {
+					m.cursor = i
+				}
+			}
+		}
+		return m, nil
+	case timeRangeMsg:
+		m.showTimeRange(msg)
+		return m, nil
+	case backupSearchMsg:
+		if m.panel == panelResults {
+			m.hits = msg.hits
+			m.hitsPattern = msg.pattern
+			m.hitsRe = msg.re
+			m.hitsTruncated = msg.truncated
+			m.loading = false
+			m.cursor = max(0, len(m.hits)-1)
+			if msg.err != nil {
+				m.notice = msg.err.Error()
+			}
+		}
+		return m, nil
+	case tea.KeyMsg:
+		keyMsg = msg
+	default:
+		return m, nil
+	}
+
+	if m.inputMode != "" {
+		return m.updateInput(keyMsg)
+	}
+	m.notice = ""
+	if m.panel != "" {
+		return m.updatePanel(keyMsg)
+	}
+	if m.selecting {
+		return m.updateSelection(keyMsg)
+	}
+
+	switch keyMsg.String() {
+	case "b":
+		return m, m.openBackups()
+	case "/", "?", "&", "t", "T":
+		return m, m.startInput(keyMsg.String())
+	case "n":
+		m.findNext(m.backward)
+	case "N":
+		m.findNext(!m.backward)
+	case "i":
+		m.toggleCase()
+	case "x":
+		m.expandLine()
+	case "v":
+		m.startSelection()
+	case "J":
+		m.rawJSON = !m.rawJSON
+		if m.rawJSON {
+			m.notice = "JSON lines shown raw"
+		} else {
+			m.notice = "JSON lines shown as fields"
+		}
+	case "j", "down":
+		m.scroll(1)
+	case "k", "up":
+		m.scroll(-1)
+	case "ctrl+d":
+		m.scroll(m.pageHeight() / 2)
+	case "ctrl+u":
+		m.scroll(-m.pageHeight() / 2)
+	case "pgdown", "ctrl+f", " ":
+		m.scroll(m.pageHeight())
+	case "pgup", "ctrl+b":
+		m.scroll(-m.pageHeight())
+	case "g", "home":
+		m.follow = false
+		m.offset = 0
+	case "G", "end":
+		m.follow = true
+		m.offset = m.maxOffset()
+	case "f":
+		m.follow = !m.follow
+		if m.follow {
+			m.offset = m.maxOffset()
+		}
+	case "tab":
+		if m.daemon {
+			m.notice = "supervisord writes a single log"
+			break
+		}
+		if m.process != nil && m.process.Logs.RedirectStderr {
+			m.notice = "stderr is redirected to stdout, both are shown"
+			break
+		}
+		if m.stream == streamStdout {
+			m.stream = streamStderr
+		} else {
+			m.stream = streamStdout
+		}
+		m.file = ""
+		m.follow = true
+		m.clearTimeRange()
+		m.Reload()
+	}
+	return m, nil
+}
+
+// View renders the log viewer
+func (m *LogViewerModel) View() string {
+	var lines []string
+
+	name := ""
+	if m.process != nil {
+		name = m.process.Name
+	}
+	title := fmt.Sprintf("Logs: %s (%s)", name, m.streamLabel())
+	if m.file != "" {
+		title = fmt.Sprintf("Logs: %s (%s, backup %s)", name, m.streamLabel(), strings.TrimPrefix(m.file, m.logPath()))
+	}
+	lines = append(lines, titleStyle.Render(title))
+
+	if m.panel != "" {
+		lines = append(lines, m.panelView()...)
+		for len(lines) < m.pageHeight()+3 {
+			lines = append(lines, "")
+		}
+		help := "j/k: move | Enter: open | Esc: back"
+		if m.panel == panelJSON {
+			help = "j/k: scroll | Esc: back"
+		} else if m.panel == panelBackups {
+			help = "j/k: move | Enter: open | /: search all | Esc: back"
+		}
+		lines = append(lines, helpStyle.Render(truncateLine(help, m.width-6)))
+		return detailPanelStyle.Width(m.width).Height(m.height).Render(strings.Join(lines, "\n"))
+	}
+
+	path := m.Path()
+	if path == "" {
+		path = "-"
+	}
+	info := fmt.Sprintf("%s  line %d-%d of %d", path,
+		min(m.offset+1, len(m.lines)), min(m.offset+m.pageHeight(), len(m.lines)), len(m.lines))
+	if len(m.lines) == viewerMaxLines {
+		info += fmt.Sprintf(" (last %d lines)", viewerMaxLines)
+	}
+	if m.follow {
+		info += "  [follow]"
+	}
+	info += m.mentionInfo()
+	lines = append(lines, labelStyle.Render(truncateLine(info, m.width-6)))
+	lines = append(lines, m.searchStatus())
+
+	if m.errorMsg != "" {
+		lines = append(lines, errorStyle.Render("Error: "+m.errorMsg))
+	}
+
+	if len(m.lines) == 0 && m.errorMsg == "" {
+		if m.timeText != "" && !m.rangeLoading {
+			lines = append(lines, valueStyle.Foreground(subtleColor).Render("No lines in the time range"))
+		} else if m.filter != nil && len(m.all) > 0 {
+			lines = append(lines, valueStyle.Foreground(subtleColor).Render("No lines match the filter"))
+		} else {
+			lines = append(lines, valueStyle.Foreground(subtleColor).Render("Log is empty"))
+		}
+	}
+
+	end := min(len(m.lines), m.offset+m.pageHeight())
+	for i := m.offset; i < end; i++ {
+		style := lineStyle(m.shown[i])
+		line := strings.ReplaceAll(m.lines[i], "\t", "    ")
+		marker := "  "
+		if m.selecting {
+			if first, last := m.selection(); i >= first && i <= last {
+				marker = selectionMarkStyle.Render("┃ ")
+			}
+		} else if i == m.hit {
+			marker = pendingStyle.Render("▶ ")
+		} else if m.mention != nil && m.mention.MatchString(plainText(line)) {
+			marker = mentionStyle.Render("● ")
+		}
+		// Matches are highlighted in the raw line, other lines may be shown as JSON fields
+		if m.search != nil && m.search.MatchString(plainText(line)) {
+			lines = append(lines, marker+highlightMatches(truncateLine(line, m.width-8), m.search, style))
+		} else {
+			lines = append(lines, marker+renderLogText(line, m.width-8, style, !m.rawJSON))
+		}
+	}
+
+	// Keep the help line at the bottom
+	for len(lines) < m.pageHeight()+3 {
+		lines = append(lines, "")
+	}
+	help := "j/k/g/G: scroll | f: follow | / ?: search | n/N: next/prev | &: filter | t/T: time | v: select | x: expand | J: json | i: case | b: backups | Tab: stream | e: $EDITOR | Esc: back"
+	lines = append(lines, helpStyle.Render(truncateLine(help, m.width-6)))
+
+	return detailPanelStyle.Width(m.width).Height(m.height).Render(strings.Join(lines, "\n"))
+}
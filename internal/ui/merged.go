@@ -0,0 +1,347 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+// sourceColors are the colors program names are prefixed with in the merged view
+var sourceColors = []lipgloss.Color{
+	lipgloss.Color("6"),  // Cyan
+	lipgloss.Color("5"),  // Magenta
+	lipgloss.Color("2"),  // Green
+	lipgloss.Color("3"),  // Yellow
+	lipgloss.Color("4"),  // Blue
+	lipgloss.Color("14"), // Bright cyan
+	lipgloss.Color("13"), // Bright magenta
+	lipgloss.Color("10"), // Bright green
+	lipgloss.Color("11"), // Bright yellow
+	lipgloss.Color("12"), // Bright blue
+}
+
+// logSource is one log stream shown in the merged view
+type logSource struct {
+	process  string
+	stream   string
+	path     string
+	reader   *tailReader
+	enabled  bool
+	style    lipgloss.Style
+	consumed int       // Lines of the reader already merged
+	resets   int       // Reader resets seen, a new one means the lines start over
+	lastTime time.Time // Time of the last merged line, inherited by lines without one
+	levels   levelClassifier
+	errorMsg string
+}
+
+// label returns the prefix of the source's lines
+func (s *logSource) label() string {
+	if s.stream == streamStderr {
+		return s.process + ":err"
+	}
+	return s.process
+}
+
+// mergedLine is a line of the merged view
+type mergedLine struct {
+	source int
+	text   string
+	level  string
+	time   time.Time // Detected timestamp, inherited or arrival time
+}
+
+// MergedLogModel shows the logs of several programs interleaved
+type MergedLogModel struct {
+	sources []*logSource
+	all     []mergedLine // Lines of all sources, oldest first
+	lines   []mergedLine // Lines of the enabled sources
+	offset  int
+	follow  bool
+	width   int
+	height  int
+}
+
+// NewMergedLogModel creates a new merged log model
+func NewMergedLogModel() *MergedLogModel {
+	return &MergedLogModel{}
+}
+
+// Open starts tailing stdout and stderr of the processes
+func (m *MergedLogModel) Open(processes []*supervisor.Process) {
+	m.sources = nil
+	m.all = nil
+	m.follow = true
+
+	seen := make(map[string]bool)
+	for _, proc := range processes {
+		for _, stream := range []string{streamStdout, streamStderr} {
+			path := proc.Logs.Stdout
+			if stream == streamStderr {
+				path = proc.Logs.Stderr
+			}
+			// Programs may share a logfile, it's only shown once
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			m.sources = append(m.sources, &logSource{
+				process: proc.Name,
+				stream:  stream,
+				path:    path,
+				reader:  newTailReader(path, viewerMaxLines),
+				enabled: true,
+				style:   lipgloss.NewStyle().Foreground(sourceColors[len(m.sources)%len(sourceColors)]).Bold(true),
+			})
+		}
+	}
+	m.Reload()
+}
+
+// Reload reads new lines of all sources and merges them
+func (m *MergedLogModel) Reload() {
+	changed := false
+	for i, s := range m.sources {
+		modTime := time.Now()
+		if info, err := os.Stat(s.path); err == nil && !s.reader.loaded {
+			// Lines from the first read without a timestamp are at most as old as the file
+			modTime = info.ModTime()
+		}
+
+		if _, err := s.reader.Read(); err != nil {
+			s.errorMsg = err.Error()
+			continue
+		}
+		s.errorMsg = ""
+
+		// A full read replaces the source's lines
+		if s.reader.resets != s.resets {
+			s.resets = s.reader.resets
+			s.consumed = 0
+			s.lastTime = time.Time{}
+			s.levels = levelClassifier{}
+			m.dropSource(i)
+			changed = true
+		}
+
+		added := s.reader.total - s.consumed
+		if added <= 0 {
+			continue
+		}
+		lines := s.reader.lines
+		if added > len(lines) {
+			added = len(lines)
+		}
+		for _, text := range lines[len(lines)-added:] {
+			// Lines without a timestamp, like stack traces, stay with the line before
+			t, ok := lineTime(text)
+			if !ok {
+				t = s.lastTime
+				if t.IsZero() {
+					t = modTime
+				}
+			}
+			s.lastTime = t
+			level, _ := s.levels.classify(text)
+			m.all = append(m.all, mergedLine{source: i, text: text, level: level, time: t})
+		}
+		s.consumed = s.reader.total
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	// Stable, so lines with the same time keep the order they arrived in
+	sort.SliceStable(m.all, func(i, j int) bool { return m.all[i].time.Before(m.all[j].time) })
+	if len(m.all) > viewerMaxLines {
+		m.all = append([]mergedLine{}, m.all[len(m.all)-viewerMaxLines:]...)
+	}
+	m.applySources()
+}
+
+// dropSource removes the merged lines of a source
+func (m *MergedLogModel) dropSource(source int) {
+	kept := m.all[:0]
+	for _, line := range m.all {
+		if line.source != source {
+			kept = append(kept, line)
+		}
+	}
+	m.all = kept
+}
+
+// applySources updates the shown lines from the enabled sources
+func (m *MergedLogModel) applySources() {
+	m.lines = nil
+	for _, line := range m.all {
+		if m.sources[line.source].enabled {
+			m.lines = append(m.lines, line)
+		}
+	}
+	if m.follow {
+		m.offset = m.maxOffset()
+	} else {
+		m.offset = min(m.offset, m.maxOffset())
+	}
+}
+
+// toggleSource shows or hides the lines of a source
+func (m *MergedLogModel) toggleSource(i int) {
+	if i < 0 || i >= len(m.sources) {
+		return
+	}
+	m.sources[i].enabled = !m.sources[i].enabled
+	m.applySources()
+}
+
+// SetSize sets the size of the merged view
+func (m *MergedLogModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	if m.follow {
+		m.offset = m.maxOffset()
+	}
+}
+
+// pageHeight returns the number of log lines visible at once
+func (m *MergedLogModel) pageHeight() int {
+	return max(3, m.height-9)
+}
+
+// maxOffset returns the offset that shows the last page
+func (m *MergedLogModel) maxOffset() int {
+	return max(0, len(m.lines)-m.pageHeight())
+}
+
+// scroll moves the view by delta lines; scrolling up stops following
+func (m *MergedLogModel) scroll(delta int) {
+	m.offset = max(0, min(m.offset+delta, m.maxOffset()))
+	if delta < 0 {
+		m.follow = false
+	}
+}
+
+// Update handles updates to the merged view
+func (m *MergedLogModel) Update(msg tea.Msg) (*MergedLogModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key := keyMsg.String(); key {
+	case "j", "down":
+		m.scroll(1)
+	case "k", "up":
+		m.scroll(-1)
+	case "ctrl+d":
+		m.scroll(m.pageHeight() / 2)
+	case "ctrl+u":
+		m.scroll(-m.pageHeight() / 2)
+	case "pgdown", "ctrl+f", " ":
+		m.scroll(m.pageHeight())
+	case "pgup", "ctrl+b":
+		m.scroll(-m.pageHeight())
+	case "g", "home":
+		m.follow = false
+		m.offset = 0
+	case "G", "end":
+		m.follow = true
+		m.offset = m.maxOffset()
+	case "f":
+		m.follow = !m.follow
+		if m.follow {
+			m.offset = m.maxOffset()
+		}
+	case "a":
+		for _, s := range m.sources {
+			s.enabled = true
+		}
+		m.applySources()
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		m.toggleSource(int(key[0] - '1'))
+	}
+	return m, nil
+}
+
+// View renders the merged view
+func (m *MergedLogModel) View() string {
+	var lines []string
+
+	var names []string
+	width := 0
+	for _, s := range m.sources {
+		if !contains(names, s.process) {
+			names = append(names, s.process)
+		}
+		width = max(width, len(s.label()))
+	}
+	lines = append(lines, titleStyle.Render("Merged logs: "+strings.Join(names, ", ")))
+
+	// Sources with their toggle key, hidden ones dimmed
+	var toggles []string
+	for i, s := range m.sources {
+		key := " "
+		if i < 9 {
+			key = fmt.Sprintf("%d", i+1)
+		}
+		if s.enabled {
+			toggles = append(toggles, key+":"+s.style.Render(s.label()))
+		} else {
+			toggles = append(toggles, helpStyle.Render(key+":"+s.label()+" (off)"))
+		}
+	}
+	lines = append(lines, truncateLine(strings.Join(toggles, "  "), m.width-6))
+
+	info := fmt.Sprintf("line %d-%d of %d", min(m.offset+1, len(m.lines)), min(m.offset+m.pageHeight(), len(m.lines)), len(m.lines))
+	if m.follow {
+		info += "  [follow]"
+	}
+	lines = append(lines, labelStyle.Render(info))
+
+	for _, s := range m.sources {
+		if s.errorMsg != "" {
+			lines = append(lines, errorStyle.Render(truncateLine(fmt.Sprintf("%s: %s", s.label(), s.errorMsg), m.width-6)))
+		}
+	}
+
+	if len(m.sources) == 0 {
+		lines = append(lines, valueStyle.Foreground(subtleColor).Render("No logfiles configured for these programs"))
+	} else if len(m.lines) == 0 {
+		lines = append(lines, valueStyle.Foreground(subtleColor).Render("Logs are empty"))
+	}
+
+	end := min(len(m.lines), m.offset+m.pageHeight())
+	for i := m.offset; i < end; i++ {
+		line := m.lines[i]
+		s := m.sources[line.source]
+		text := strings.ReplaceAll(line.text, "\t", "    ")
+		style := lineStyle(line.level)
+		lines = append(lines, s.style.Render(fmt.Sprintf("%-*s", width, s.label()))+" │ "+renderLogText(text, m.width-width-9, style, true))
+	}
+
+	// Keep the help line at the bottom
+	for len(lines) < m.pageHeight()+3 {
+		lines = append(lines, "")
+	}
+	help := "j/k/g/G: scroll | f: follow | 1-9: toggle source | a: show all | Esc: back"
+	lines = append(lines, helpStyle.Render(truncateLine(help, m.width-6)))
+
+	return detailPanelStyle.Width(m.width).Height(m.height).Render(strings.Join(lines, "\n"))
+}
+
+// contains returns true if list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
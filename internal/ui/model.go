@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -23,6 +24,12 @@ const (
 	ModeAdd
 	ModeDelete
 	ModeViewLogs
+	ModeHistory
+	ModeConfirm
+	ModePrompt
+	ModeMessage
+	ModeTemplate
+	ModeMergedLogs
 )
 
 // refreshMsg is sent periodically to refresh process status
@@ -31,6 +38,40 @@ type refreshMsg struct{}
 // clearStatusMsg clears the status message
 type clearStatusMsg struct{}
 
+// logTickMsg reloads the log viewer while it is open
+type logTickMsg struct {
+	generation int // Ticks of a previously opened viewer are dropped
+}
+
+// editorFinishedMsg is sent when an external editor exits
+type editorFinishedMsg struct {
+	err error
+}
+
+// pendingChangesMsg carries the config changes not yet applied by supervisord
+type pendingChangesMsg struct {
+	changes   supervisor.ConfigChanges
+	err       error
+	stamp     string // ConfigStamp of the checked config files
+	unchanged bool   // The config files didn't change, so supervisord wasn't asked
+}
+
+// pendingRecheck is how often pending changes are checked even if the config
+// files didn't change, e.g. because another session applied them
+const pendingRecheck = 2 * time.Minute
+
+// logScanMsg carries what a background scan of the process logs found
+type logScanMsg struct {
+	errorCounts map[string]int
+	alerts      []Alert
+}
+
+// logFilesMsg carries the log files supervisord reports for its processes
+type logFilesMsg struct {
+	files map[string]supervisor.LogFiles
+	err   error
+}
+
 // processActionMsg is sent when a process action completes
 type processActionMsg struct {
 	processName string
@@ -38,25 +79,67 @@ type processActionMsg struct {
 	err         error
 }
 
+// confirmAction is a pending yes/no question shown in ModeConfirm
+type confirmAction struct {
+	title      string
+	message    string
+	onYes      func() (tea.Model, tea.Cmd)
+	returnMode Mode // Mode to return to when the question is declined
+}
+
+// promptAction is a pending single-line input shown in ModePrompt
+type promptAction struct {
+	title      string
+	input      textinput.Model
+	onSubmit   func(value string) (tea.Model, tea.Cmd)
+	returnMode Mode // Mode to return to when the prompt is cancelled
+}
+
+// draft is a generated program config waiting for review in the editor
+type draft struct {
+	config   *supervisor.ProcessConfig
+	warnings []string
+}
+
 // Model represents the main application model
 type Model struct {
-	listModel   *ListModel
-	detailModel *DetailModel
-	editorModel *EditorModel
-	client      *supervisor.Client
-	config      *supervisor.Config
-	configPath  string
-	processes   []*supervisor.Process
+	listModel     *ListModel
+	detailModel   *DetailModel
+	editorModel   *EditorModel
+	historyModel  *HistoryModel
+	templateModel *TemplateModel
+	logViewer     *LogViewerModel
+	mergedModel   *MergedLogModel
+	errorCounter  *ErrorCounter
+	alertWatcher  *AlertWatcher
+	logTicks      int // Generation of the log viewer reload ticks
+	client        *supervisor.Client
+	config        *supervisor.Config
+	configPath    string
+	socketURL     string // supervisord's socket, asked for the log files it writes
+	processes     []*supervisor.Process
+	reported      map[string]supervisor.LogFiles // Log files reported by supervisord, nil if unknown
+	fetchingLogs  bool                           // A request for the reported log files is running
+	scanningLogs  bool                           // A scan of the logs for errors and alerts is running
 
 	mode          Mode
 	searchInput   textinput.Model
 	deleteConfirm bool
+	confirm       *confirmAction
+	prompt        *promptAction
+	message       string // Text shown in ModeMessage
+	messageTitle  string
+	drafts        []draft // Generated configs queued for review
+	draftTotal    int
 
 	width          int
 	height         int
 	err            error
-	statusMsg      string            // Temporary status message (e.g., "Stopping process...")
-	pendingActions map[string]string // processName -> action (e.g., "STARTING", "STOPPING", "RESTARTING")
+	statusMsg      string                   // Temporary status message (e.g., "Stopping process...")
+	pendingActions map[string]string        // processName -> action (e.g., "STARTING", "STOPPING", "RESTARTING")
+	pending        supervisor.ConfigChanges // Config changes on disk not applied yet
+	pendingStamp   string                   // ConfigStamp of the config files when pending was checked
+	pendingChecked time.Time
 }
 
 // InitialModel creates the initial model with auto-detected config
@@ -123,11 +206,20 @@ func InitialModelWithConfig(configPath string) (*Model, error) {
 			proc.Config = cfg
 		}
 	}
+	// Log files reported by supervisord are fetched in the background, see Init
+	supervisor.ResolveLogFiles(processes, config, nil)
 
 	// Initialize models
 	listModel := NewListModel(processes)
+	// Logs are scanned for errors in the background, see Init
+	errorCounter := NewErrorCounter()
+	// The first scan only skips the lines already logged
+	rules, rulesErr := supervisor.LoadAlertRules()
+	alertWatcher := NewAlertWatcher(rules)
 	detailModel := NewDetailModel()
 	editorModel := NewEditorModel()
+	historyModel := NewHistoryModel()
+	templateModel := NewTemplateModel()
 
 	// Initialize search input
 	searchInput := textinput.New()
@@ -137,9 +229,16 @@ func InitialModelWithConfig(configPath string) (*Model, error) {
 		listModel:      listModel,
 		detailModel:    detailModel,
 		editorModel:    editorModel,
+		historyModel:   historyModel,
+		templateModel:  templateModel,
+		logViewer:      NewLogViewerModel(),
+		mergedModel:    NewMergedLogModel(),
+		errorCounter:   errorCounter,
+		alertWatcher:   alertWatcher,
 		client:         client,
 		config:         config,
 		configPath:     configPath,
+		socketURL:      supervisor.SocketPath(configPath),
 		processes:      processes,
 		mode:           ModeList,
 		searchInput:    searchInput,
@@ -152,6 +251,9 @@ func InitialModelWithConfig(configPath string) (*Model, error) {
 	if len(processes) > 0 {
 		model.updateDetailView()
 	}
+	if rulesErr != nil {
+		model.showMessage("Alert rules not loaded", rulesErr.Error())
+	}
 
 	return model, nil
 }
@@ -163,6 +265,9 @@ func (m *Model) Init() tea.Cmd {
 		m.editorModel.Init(),
 		textinput.Blink,
 		m.refreshTick(),
+		m.checkPendingChanges(0),
+		m.fetchLogFiles(),
+		m.scanLogs(),
 	)
 }
 
@@ -173,6 +278,67 @@ func (m *Model) refreshTick() tea.Cmd {
 	})
 }
 
+// checkPendingChanges returns a command that looks for unapplied config changes after a delay
+// supervisorctl reread parses every config file, so it only runs when the files
+// changed since the last check or pendingRecheck has passed.
+func (m *Model) checkPendingChanges(delay time.Duration) tea.Cmd {
+	client, configPath := m.client, m.configPath
+	stamp, checked := m.pendingStamp, m.pendingChecked
+	check := func() tea.Msg {
+		current := supervisor.ConfigStamp(configPath)
+		if current == stamp && time.Since(checked) < pendingRecheck {
+			return pendingChangesMsg{unchanged: true}
+		}
+		changes, err := client.PendingChanges()
+		return pendingChangesMsg{changes: changes, err: err, stamp: current}
+	}
+	if delay == 0 {
+		return check
+	}
+	return tea.Tick(delay, func(time.Time) tea.Msg { return check() })
+}
+
+// nameInUse returns true if a program or group called name is defined in the
+// loaded config, known to supervisord or waiting to be added by update
+func (m *Model) nameInUse(name string) bool {
+	if m.config.GetProcessConfig(name) != nil || m.pending[name] != "" {
+		return true
+	}
+	for _, proc := range m.processes {
+		if proc.Name == name || strings.HasPrefix(proc.Name, name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// withAvailRows adds rows for programs that were added on disk but not to supervisord yet,
+// since supervisorctl status doesn't list them
+func (m *Model) withAvailRows(processes []*supervisor.Process) []*supervisor.Process {
+	for _, name := range m.pending.Names() {
+		if m.pending[name] != supervisor.ChangeAdded {
+			continue
+		}
+		found := false
+		for _, proc := range processes {
+			if proc.Name == name || strings.HasPrefix(proc.Name, name+":") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			proc := &supervisor.Process{
+				Name:   name,
+				Status: "AVAIL",
+				Config: m.config.GetProcessConfig(name),
+			}
+			supervisor.ResolveLogFiles([]*supervisor.Process{proc}, m.config, nil)
+			processes = append(processes, proc)
+		}
+	}
+	return processes
+}
+
 // Update handles updates
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -208,6 +374,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					proc.Config = cfg
 				}
 			}
+			supervisor.ResolveLogFiles(processes, m.config, m.reported)
 			// Apply pending actions to processes before displaying
 			for _, proc := range processes {
 				if pendingStatus, ok := m.pendingActions[proc.Name]; ok {
@@ -215,7 +382,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			m.processes = processes
-			m.listModel.SetProcesses(processes)
+			m.listModel.SetProcesses(m.withAvailRows(processes))
 			m.updateDetailView()
 		}
 		// Set error if present, but don't clear processes
@@ -224,15 +391,42 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.err = nil // Clear error on successful refresh
 		}
-		return m, m.refreshTick()
+		return m, tea.Batch(m.refreshTick(), m.fetchLogFiles(), m.scanLogs())
+
+	case logScanMsg:
+		m.scanningLogs = false
+		m.listModel.SetErrorCounts(msg.errorCounts)
+		m.alertWatcher.Record(msg.alerts)
+		m.listModel.SetAlertCounts(m.alertWatcher.Counts())
+		return m, tea.Batch(m.alertWatcher.commands(msg.alerts)...)
+
+	case logFilesMsg:
+		// Without the socket, AUTO logfiles are looked up in childlogdir instead
+		m.fetchingLogs = false
+		m.reported = msg.files
+		supervisor.ResolveLogFiles(m.processes, m.config, m.reported)
+		m.updateDetailView()
+		return m, nil
+
+	case alertCommandMsg:
+		text := fmt.Sprintf("Alert command of %s failed: %v", msg.alert.Rule.Name, msg.err)
+		if msg.output != "" {
+			text += ": " + strings.SplitN(msg.output, "\n", 2)[0]
+		}
+		return m, m.setStatusMsg(text)
 
 	case processActionMsg:
 		// Handle async process action completion
 		if msg.err != nil {
-			m.err = msg.err
-			m.setStatusMsg(fmt.Sprintf("Failed to %s %s", msg.action, msg.processName))
 			// Remove pending action on error
 			delete(m.pendingActions, msg.processName)
+			if cmd, ok := m.requestSudo(msg.err, func() (tea.Model, tea.Cmd) {
+				return m, m.processActionAsync(msg.action, msg.processName)
+			}); ok {
+				return m, cmd
+			}
+			m.err = msg.err
+			m.setStatusMsg(fmt.Sprintf("Failed to %s %s", msg.action, msg.processName))
 		} else {
 			m.setStatusMsg(fmt.Sprintf("%s %s", strings.Title(msg.action), msg.processName))
 			// Remove pending action
@@ -242,10 +436,53 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case clearStatusMsg:
+		m.statusMsg = ""
+		return m, nil
+
+	case logTickMsg:
+		// Stop ticking once the viewer is closed
+		if msg.generation != m.logTicks {
+			return m, nil
+		}
+		switch m.mode {
+		case ModeViewLogs:
+			m.logViewer.Reload()
+		case ModeMergedLogs:
+			m.mergedModel.Reload()
+		default:
+			return m, nil
+		}
+		return m, m.logTick()
+
+	case backupsMsg, backupSearchMsg, timeRangeMsg:
+		updatedViewer, viewerCmd := m.logViewer.Update(msg)
+		m.logViewer = updatedViewer
+		return m, viewerCmd
+
+	case editorFinishedMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("editor failed: %w", msg.err)
+		}
+		return m, nil
+
+	case pendingChangesMsg:
+		// Checking needs access to the supervisord socket, so errors are
+		// not shown here, supervisorctl status reports them already
+		if msg.err == nil && !msg.unchanged {
+			m.pendingStamp = msg.stamp
+			m.pendingChecked = time.Now()
+			m.pending = msg.changes
+			m.listModel.SetPending(m.pending)
+			m.listModel.SetProcesses(m.withAvailRows(m.processes))
+			m.updateDetailView()
+		}
+		return m, m.checkPendingChanges(15 * time.Second)
+
 	case tea.KeyMsg:
 		handled, model, keyCmd := m.handleKeyPress(msg)
 		if handled {
-			return model, keyCmd
+			return model, tea.Batch(keyCmd, m.historyNotice())
 		}
 
 		// Handle mode-specific updates
@@ -262,6 +499,34 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			updatedEditor, editCmd := m.editorModel.Update(msg)
 			m.editorModel = updatedEditor
 			return m, editCmd
+
+		case ModeHistory:
+			updatedHistory, historyCmd := m.historyModel.Update(msg)
+			m.historyModel = updatedHistory
+			return m, historyCmd
+
+		case ModeTemplate:
+			updatedTemplates, templateCmd := m.templateModel.Update(msg)
+			m.templateModel = updatedTemplates
+			return m, templateCmd
+
+		case ModeViewLogs:
+			updatedViewer, viewerCmd := m.logViewer.Update(msg)
+			m.logViewer = updatedViewer
+			return m, viewerCmd
+
+		case ModeMergedLogs:
+			updatedMerged, mergedCmd := m.mergedModel.Update(msg)
+			m.mergedModel = updatedMerged
+			return m, mergedCmd
+
+		case ModeConfirm:
+			return m, nil
+
+		case ModePrompt:
+			var promptCmd tea.Cmd
+			m.prompt.input, promptCmd = m.prompt.input.Update(msg)
+			return m, promptCmd
 		}
 
 		// List mode updates
@@ -305,6 +570,8 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (bool, tea.Model, tea.Cmd) {
 		case "esc":
 			m.mode = ModeList
 			m.editorModel.SetConfig(nil)
+			// Skip to the next generated config, if any
+			m.openNextDraft()
 			return true, m, nil
 		}
 		// Let editor handle other keys (including Enter for newlines)
@@ -322,6 +589,106 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (bool, tea.Model, tea.Cmd) {
 		}
 		return false, m, nil
 
+	case ModeHistory:
+		switch msg.String() {
+		case "r":
+			entry := m.historyModel.GetSelected()
+			if entry != nil {
+				m.askConfirm("Confirm Restore",
+					fmt.Sprintf("Restore %s to the version from %s?", entry.Path, entry.Time.Format("2006-01-02 15:04:05")),
+					func() (tea.Model, tea.Cmd) { return m.restoreHistory(entry) })
+			}
+			return true, m, nil
+		case "esc", "q":
+			m.mode = ModeList
+			return true, m, nil
+		}
+		return false, m, nil
+
+	case ModeTemplate:
+		switch msg.String() {
+		case "enter":
+			if !m.templateModel.Filling() {
+				return true, m, m.templateModel.Pick()
+			}
+			m.mode = ModeAdd
+			m.editorModel.SetTemplate(m.templateModel.Render())
+			return true, m, nil
+		case "esc":
+			if m.templateModel.Filling() {
+				m.templateModel.Back()
+			} else {
+				m.mode = ModeList
+			}
+			return true, m, nil
+		}
+		return false, m, nil
+
+	case ModeViewLogs:
+		// Keys go to the search input while a pattern is typed
+		if m.logViewer.Capturing() {
+			return false, m, nil
+		}
+		switch msg.String() {
+		case "e":
+			return true, m, m.editLogs()
+		case "esc", "q":
+			// Esc clears an active search or filter first
+			if msg.String() == "esc" && m.logViewer.Escape() {
+				return true, m, nil
+			}
+			m.mode = ModeList
+			return true, m, nil
+		}
+		return false, m, nil
+
+	case ModeMergedLogs:
+		switch msg.String() {
+		case "esc", "q":
+			m.mode = ModeList
+			return true, m, nil
+		}
+		return false, m, nil
+
+	case ModeConfirm:
+		switch msg.String() {
+		case "y", "Y":
+			action := m.confirm
+			m.confirm = nil
+			m.mode = ModeList
+			model, cmd := action.onYes()
+			return true, model, cmd
+		case "n", "N", "esc":
+			m.mode = m.confirm.returnMode
+			m.confirm = nil
+			return true, m, nil
+		}
+		return false, m, nil
+
+	case ModeMessage:
+		switch msg.String() {
+		case "enter", "esc", "q":
+			m.mode = ModeList
+			m.message = ""
+			return true, m, nil
+		}
+		return true, m, nil
+
+	case ModePrompt:
+		switch msg.String() {
+		case "enter":
+			action := m.prompt
+			m.prompt = nil
+			m.mode = action.returnMode
+			model, cmd := action.onSubmit(action.input.Value())
+			return true, model, cmd
+		case "esc":
+			m.mode = m.prompt.returnMode
+			m.prompt = nil
+			return true, m, nil
+		}
+		return false, m, nil
+
 	case ModeList:
 		handled, model, cmd := m.handleListKeyPress(msg)
 		return handled, model, cmd
@@ -330,6 +697,20 @@ func (m *Model) handleKeyPress(msg tea.KeyMsg) (bool, tea.Model, tea.Cmd) {
 	return false, m, nil
 }
 
+// listKeyHelp lists the keys of the process list, shown by ?
+const listKeyHelp = `j/k      move down/up             space    mark/unmark
+/        search                   *        mark all/clear marks
+s        start                    X        export marked programs
+x        stop                     I        import programs
+r        restart                  u        apply pending config changes
+a        add from a template      h        config history
+e        edit program             H        history of all programs
+E        edit config file         S        operations run with sudo
+c        clone program            D        supervisord's log
+d        delete program           !        alerts
+l        stdout log               M        merged logs of marked programs
+L        stderr log               q        quit`
+
 // handleListKeyPress handles key presses in list mode
 func (m *Model) handleListKeyPress(msg tea.KeyMsg) (bool, tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -389,8 +770,25 @@ func (m *Model) handleListKeyPress(msg tea.KeyMsg) (bool, tea.Model, tea.Cmd) {
 		return true, m, nil
 
 	case "a":
-		m.mode = ModeAdd
-		m.editorModel.SetConfig(nil) // nil means new process with template
+		m.mode = ModeTemplate
+		m.templateModel.Load()
+		return true, m, nil
+
+	case "E":
+		proc := m.listModel.GetSelected()
+		if proc == nil {
+			return true, m, nil
+		}
+		if proc.Config == nil || proc.Config.SourceFile == "" {
+			return true, m, m.setStatusMsg(fmt.Sprintf("No config file found for %s", proc.Name))
+		}
+		content, err := supervisor.ReadConfigFile(proc.Config.SourceFile)
+		if err != nil {
+			m.err = err
+			return true, m, nil
+		}
+		m.mode = ModeEdit
+		m.editorModel.SetFile(proc.Config.SourceFile, content)
 		return true, m, nil
 
 	case "e":
@@ -423,6 +821,19 @@ func (m *Model) handleListKeyPress(msg tea.KeyMsg) (bool, tea.Model, tea.Cmd) {
 		}
 		return true, m, nil
 
+	case "c":
+		proc := m.listModel.GetSelected()
+		if proc == nil {
+			return true, m, nil
+		}
+		if proc.Config == nil {
+			return true, m, m.setStatusMsg(fmt.Sprintf("No config found for %s", proc.Name))
+		}
+		clone := supervisor.CloneProgram(proc.Config, m.nameInUse)
+		m.editorModel.SetDraft(clone, fmt.Sprintf("Clone of %s", proc.Name), nil)
+		m.mode = ModeAdd
+		return true, m, nil
+
 	case "d":
 		proc := m.listModel.GetSelected()
 		if proc != nil {
@@ -431,17 +842,94 @@ func (m *Model) handleListKeyPress(msg tea.KeyMsg) (bool, tea.Model, tea.Cmd) {
 		}
 		return true, m, nil
 
+	case " ":
+		m.listModel.ToggleMark()
+		return true, m, nil
+
+	case "*":
+		m.listModel.ToggleMarkAll()
+		return true, m, nil
+
+	case "X":
+		if len(m.listModel.GetTargets()) > 0 {
+			return true, m, m.askInput("Export Programs (.yaml, .json, .service, Procfile or a directory of units)", "god-export.yaml", m.exportPrograms)
+		}
+		return true, m, nil
+
+	case "S":
+		m.showElevated()
+		return true, m, nil
+
+	case "u":
+		if len(m.pending) == 0 {
+			return true, m, m.setStatusMsg("No pending config changes")
+		}
+		var lines []string
+		for _, name := range m.pending.Names() {
+			lines = append(lines, fmt.Sprintf("  %s %s", pendingBadges[m.pending[name]], name))
+		}
+		m.askConfirm("Apply Pending Changes",
+			"Run update for these programs? Changed programs are restarted.\n"+strings.Join(lines, "\n"),
+			m.applyPending)
+		return true, m, nil
+
+	case "I":
+		return true, m, m.askInput("Import Programs (YAML, JSON, Procfile or .service)", "god-export.yaml", m.importPrograms)
+
+	case "h":
+		proc := m.listModel.GetSelected()
+		if proc != nil {
+			m.historyModel.Load(proc.Name)
+			m.mode = ModeHistory
+		}
+		return true, m, nil
+
+	case "H":
+		m.historyModel.Load("")
+		m.mode = ModeHistory
+		return true, m, nil
+
 	case "l":
 		proc := m.listModel.GetSelected()
-		if proc != nil && proc.Config != nil {
-			m.viewLogs(proc, "stdout")
+		if proc != nil {
+			return true, m, m.openLogs(proc, streamStdout)
 		}
 		return true, m, nil
 
 	case "L":
 		proc := m.listModel.GetSelected()
-		if proc != nil && proc.Config != nil {
-			m.viewLogs(proc, "stderr")
+		if proc != nil {
+			return true, m, m.openLogs(proc, streamStderr)
+		}
+		return true, m, nil
+
+	case "D":
+		path, reason := supervisor.DaemonLogFile(m.config)
+		if path == "" {
+			return true, m, m.setStatusMsg(reason)
+		}
+		m.logViewer.OpenDaemonLog(path, m.listModel.GetSelected())
+		m.mode = ModeViewLogs
+		m.logTicks++
+		return true, m, m.logTick()
+
+	case "!":
+		m.showMessage("Alerts", m.alertWatcher.alertList(max(1, m.height-10)))
+		m.alertWatcher.Acknowledge()
+		m.listModel.SetAlertCounts(m.alertWatcher.Counts())
+		return true, m, nil
+
+	case "?":
+		m.showMessage("Keys", listKeyHelp)
+		return true, m, nil
+
+	case "M":
+		targets := m.listModel.GetTargets()
+		if len(targets) > 0 {
+			m.mergedModel.Open(targets)
+			m.mode = ModeMergedLogs
+			m.logTicks++
+			return true, m, m.logTick()
 		}
 		return true, m, nil
 	}
@@ -477,16 +965,53 @@ func (m *Model) refreshProcesses() {
 				proc.Status = pendingStatus
 			}
 		}
+		supervisor.ResolveLogFiles(processes, m.config, m.reported)
 		m.processes = processes
-		m.listModel.SetProcesses(processes)
+		m.listModel.SetProcesses(m.withAvailRows(processes))
 		m.updateDetailView()
 	}
 }
 
+// fetchLogFiles returns a command that asks supervisord for the files it
+// picked for AUTO logfiles, unless a request is still running
+func (m *Model) fetchLogFiles() tea.Cmd {
+	if m.fetchingLogs {
+		return nil
+	}
+	m.fetchingLogs = true
+	client, socketURL := m.client, m.socketURL
+	return func() tea.Msg {
+		files, err := client.ReportedLogFiles(socketURL)
+		return logFilesMsg{files: files, err: err}
+	}
+}
+
+// scanLogs returns a command that reads new lines of the processes' logs, counts
+// their errors and matches them against the alert rules in the background,
+// unless a scan is still running
+func (m *Model) scanLogs() tea.Cmd {
+	if m.scanningLogs {
+		return nil
+	}
+	m.scanningLogs = true
+
+	// The scan works on copies, the processes are updated while it runs
+	processes := make([]*supervisor.Process, len(m.processes))
+	for i, proc := range m.processes {
+		copied := *proc
+		processes[i] = &copied
+	}
+	counter, watcher := m.errorCounter, m.alertWatcher
+	return func() tea.Msg {
+		return logScanMsg{errorCounts: counter.Update(processes), alerts: watcher.Scan(processes)}
+	}
+}
+
 // updateDetailView updates the detail view with the currently selected process
 func (m *Model) updateDetailView() {
 	proc := m.listModel.GetSelected()
 	if proc != nil {
+		m.detailModel.SetPending(m.pending.For(proc.Name))
 		m.detailModel.SetProcess(proc)
 	}
 }
@@ -541,31 +1066,62 @@ func (m *Model) updateSizes() {
 	m.listModel.SetSize(listWidth, panelHeight)
 	m.detailModel.SetSize(rightWidth, panelHeight)
 	m.editorModel.SetSize(m.width-4, m.height-4)
+	m.historyModel.SetSize(m.width-4, m.height-4)
+	m.templateModel.SetSize(m.width-4, m.height-4)
+	m.logViewer.SetSize(m.width-4, m.height-4)
+	m.mergedModel.SetSize(m.width-4, m.height-4)
 }
 
 // saveProcess saves the current process from the editor
 func (m *Model) saveProcess() (tea.Model, tea.Cmd) {
+	if m.editorModel.FilePath() != "" {
+		return m.saveFile()
+	}
+
 	config, err := m.editorModel.GetConfig()
 	if err != nil {
 		m.editorModel.SetError(err.Error())
 		return m, nil
 	}
 
-	// Save process config to conf.d/{process-name}.conf
-	if err := supervisor.SaveProcessConfig(config); err != nil {
-		m.editorModel.SetError(err.Error())
+	// Changing the [program:name] header of an existing program is a rename
+	if oldName := m.editorModel.OriginalName(); m.mode == ModeEdit && oldName != "" && oldName != config.Name {
+		if m.nameInUse(config.Name) {
+			m.editorModel.SetError(fmt.Sprintf("a program named %s already exists", config.Name))
+			return m, nil
+		}
+		m.askConfirm("Confirm Rename",
+			fmt.Sprintf("Rename '%s' to '%s'? The old program will be stopped and its config removed.", oldName, config.Name),
+			func() (tea.Model, tea.Cmd) { return m.renameProcess(oldName, config) })
 		return m, nil
 	}
 
-	// Reread config files
-	if err := m.client.Reread(); err != nil {
-		m.editorModel.SetError(fmt.Sprintf("failed to reread config: %v", err))
+	// New programs (added, cloned or generated) must not replace an existing one
+	if m.mode == ModeAdd && m.nameInUse(config.Name) {
+		m.editorModel.SetError(fmt.Sprintf("a program named %s already exists", config.Name))
 		return m, nil
 	}
 
-	// Update the process (adds new processes, updates existing ones)
-	if err := m.client.Update(config.Name); err != nil {
-		m.editorModel.SetError(fmt.Sprintf("failed to update process: %v", err))
+	// Save process config where it is defined (or to conf.d/{process-name}.conf)
+	if err := supervisor.SaveProcessConfig(config); err != nil {
+		if cmd, ok := m.requestSudo(err, m.saveProcess); ok {
+			return m, cmd
+		}
+		m.editorModel.SetError(err.Error())
+		return m, nil
+	}
+
+	return m.finishSave(config)
+}
+
+// finishSave applies a saved process config and returns to the list
+func (m *Model) finishSave(config *supervisor.ProcessConfig) (tea.Model, tea.Cmd) {
+	// Reread config files and update the process (adds new processes, updates existing ones)
+	if err := m.applyConfig(config.Name); err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.finishSave(config) }); ok {
+			return m, cmd
+		}
+		m.editorModel.SetError(err.Error())
 		return m, nil
 	}
 
@@ -582,6 +1138,11 @@ func (m *Model) saveProcess() (tea.Model, tea.Cmd) {
 	m.editorModel.SetConfig(nil)
 	m.refreshProcesses()
 
+	// Continue with the next generated config, if any
+	if m.openNextDraft() {
+		return m, nil
+	}
+
 	// Select the saved process
 	for i, proc := range m.processes {
 		if proc.Name == config.Name {
@@ -594,6 +1155,100 @@ func (m *Model) saveProcess() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// saveFile saves a config file edited as a whole and applies all affected sections
+func (m *Model) saveFile() (tea.Model, tea.Cmd) {
+	path := m.editorModel.FilePath()
+	content, original := m.editorModel.FileContent()
+
+	names, err := supervisor.SaveConfigText(path, original, content)
+	if err != nil {
+		if cmd, ok := m.requestSudo(err, m.saveFile); ok {
+			return m, cmd
+		}
+		m.editorModel.SetError(err.Error())
+		return m, nil
+	}
+
+	return m.finishSaveFile(path, names)
+}
+
+// finishSaveFile applies a saved config file and returns to the list
+func (m *Model) finishSaveFile(path string, names []string) (tea.Model, tea.Cmd) {
+	if err := m.applyConfig(names...); err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.finishSaveFile(path, names) }); ok {
+			return m, cmd
+		}
+		m.editorModel.SetError(err.Error())
+		return m, nil
+	}
+
+	m.mode = ModeList
+	m.editorModel.SetConfig(nil)
+	m.refreshProcesses()
+	m.updateDetailView()
+	return m, m.setStatusMsg(fmt.Sprintf("Saved %s (%s)", path, strings.Join(names, ", ")))
+}
+
+// renameProcess replaces program oldName with config under its new name
+// The new config is written first, the old program is only stopped once that succeeded
+func (m *Model) renameProcess(oldName string, config *supervisor.ProcessConfig) (tea.Model, tea.Cmd) {
+	// Replace the old config with the new one, a failure returns to the
+	// editor so the change isn't lost and the old program keeps running
+	if err := supervisor.RenameProcessConfig(oldName, config); err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.renameProcess(oldName, config) }); ok {
+			return m, cmd
+		}
+		m.mode = ModeEdit
+		m.editorModel.SetError(err.Error())
+		return m, nil
+	}
+
+	return m.stopRenamed(oldName, config)
+}
+
+// stopRenamed stops the old program of a rename whose config was written already
+func (m *Model) stopRenamed(oldName string, config *supervisor.ProcessConfig) (tea.Model, tea.Cmd) {
+	// It's fine if it isn't running, and update removes it in any case
+	err := m.client.Stop(oldName)
+	if err != nil && !strings.Contains(err.Error(), "not running") && !strings.Contains(err.Error(), "no such process") {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.stopRenamed(oldName, config) }); ok {
+			return m, cmd
+		}
+		model, cmd := m.finishRename(oldName, config)
+		return model, tea.Batch(cmd, m.setStatusMsg(fmt.Sprintf("Failed to stop %s: %v", oldName, err)))
+	}
+
+	return m.finishRename(oldName, config)
+}
+
+// finishRename applies a renamed process config and returns to the list
+func (m *Model) finishRename(oldName string, config *supervisor.ProcessConfig) (tea.Model, tea.Cmd) {
+	// Remove the old program and add the new one
+	if err := m.applyConfig(oldName, config.Name); err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.finishRename(oldName, config) }); ok {
+			return m, cmd
+		}
+		m.mode = ModeEdit
+		m.editorModel.SetError(err.Error())
+		return m, nil
+	}
+
+	m.mode = ModeList
+	m.editorModel.SetConfig(nil)
+	m.refreshProcesses()
+
+	// Select the renamed process
+	for i, proc := range m.processes {
+		if proc.Name == config.Name {
+			m.listModel.SetSelected(i)
+			break
+		}
+	}
+
+	m.updateDetailView()
+	return m, m.setStatusMsg(fmt.Sprintf("Renamed %s to %s", oldName, config.Name))
+}
+
 // confirmDelete confirms and deletes the selected process
 func (m *Model) confirmDelete() (tea.Model, tea.Cmd) {
 	proc := m.listModel.GetSelected()
@@ -602,22 +1257,30 @@ func (m *Model) confirmDelete() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// Delete the process config file from conf.d
-	if err := supervisor.DeleteProcessConfig(proc.Name); err != nil {
-		m.err = err
-		m.mode = ModeList
-		return m, nil
+	// Delete the program from the file it is defined in
+	config := proc.Config
+	if config == nil {
+		config = &supervisor.ProcessConfig{Name: proc.Name}
 	}
-
-	// Reread config files
-	if err := m.client.Reread(); err != nil {
+	if err := supervisor.DeleteProcessConfig(config); err != nil {
+		if cmd, ok := m.requestSudo(err, m.confirmDelete); ok {
+			return m, cmd
+		}
 		m.err = err
 		m.mode = ModeList
 		return m, nil
 	}
 
-	// Update to remove the process
-	if err := m.client.Update(""); err != nil {
+	return m.finishDelete()
+}
+
+// finishDelete removes a deleted process from supervisord and returns to the list
+func (m *Model) finishDelete() (tea.Model, tea.Cmd) {
+	// Reread config files and update to remove the process
+	if err := m.applyConfig(); err != nil {
+		if cmd, ok := m.requestSudo(err, m.finishDelete); ok {
+			return m, cmd
+		}
 		m.err = err
 		m.mode = ModeList
 		return m, nil
@@ -647,22 +1310,360 @@ func (m *Model) confirmDelete() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// viewLogs opens the log file in the default editor
-// logType can be "stdout" or "stderr"
-func (m *Model) viewLogs(proc *supervisor.Process, logType string) {
-	if proc.Config == nil {
+// askConfirm switches to confirmation mode with a yes/no question
+func (m *Model) askConfirm(title, message string, onYes func() (tea.Model, tea.Cmd)) {
+	m.confirm = &confirmAction{
+		title:      title,
+		message:    message,
+		onYes:      onYes,
+		returnMode: m.mode,
+	}
+	m.mode = ModeConfirm
+}
+
+// showMessage switches to a view showing an informational message
+func (m *Model) showMessage(title, message string) {
+	m.messageTitle = title
+	m.message = message
+	m.mode = ModeMessage
+}
+
+// askInput switches to prompt mode with a single-line input
+func (m *Model) askInput(title, value string, onSubmit func(string) (tea.Model, tea.Cmd)) tea.Cmd {
+	input := textinput.New()
+	input.SetValue(value)
+	input.CursorEnd()
+	input.Width = m.width - 12
+	input.Focus()
+
+	m.prompt = &promptAction{
+		title:      title,
+		input:      input,
+		onSubmit:   onSubmit,
+		returnMode: m.mode,
+	}
+	m.mode = ModePrompt
+	return textinput.Blink
+}
+
+// applyConfig rereads config files and updates the given programs (all programs if none are given)
+func (m *Model) applyConfig(names ...string) error {
+	if _, err := m.client.Reread(); err != nil {
+		return err
+	}
+	if err := m.client.Update(names...); err != nil {
+		return err
+	}
+
+	// The applied programs are in sync with the disk now
+	if len(names) == 0 {
+		m.pending = nil
+	}
+	for _, name := range names {
+		delete(m.pending, name)
+	}
+	m.listModel.SetPending(m.pending)
+	return nil
+}
+
+// applyPending runs update for the programs with config changes not applied yet
+func (m *Model) applyPending() (tea.Model, tea.Cmd) {
+	names := m.pending.Names()
+	if err := m.applyConfig(names...); err != nil {
+		if cmd, ok := m.requestSudo(err, m.applyPending); ok {
+			return m, cmd
+		}
+		m.err = err
+		return m, nil
+	}
+
+	m.refreshProcesses()
+	return m, m.setStatusMsg(fmt.Sprintf("Applied changes to %s", strings.Join(names, ", ")))
+}
+
+// requestSudo asks for the sudo password if err was caused by missing permissions,
+// then runs retry with sudo enabled. It returns false for any other error.
+func (m *Model) requestSudo(err error, retry func() (tea.Model, tea.Cmd)) (tea.Cmd, bool) {
+	if !supervisor.IsPermissionError(err) || supervisor.SudoEnabled() {
+		return nil, false
+	}
+
+	cmd := m.askInput("Permission denied, enter your password to retry with sudo", "", func(password string) (tea.Model, tea.Cmd) {
+		if err := supervisor.EnableSudo(password); err != nil {
+			m.err = err
+			return m, nil
+		}
+		model, cmd := retry()
+		return model, tea.Batch(cmd, m.setStatusMsg("Using sudo for privileged operations (S: show log)"))
+	})
+	m.prompt.input.EchoMode = textinput.EchoPassword
+	m.prompt.input.EchoCharacter = '•'
+	return cmd, true
+}
+
+// showElevated shows the operations that were performed through sudo
+func (m *Model) showElevated() {
+	ops := supervisor.ElevatedOperations()
+	if len(ops) == 0 {
+		m.showMessage("Elevated Operations", "No operations have been run with sudo.")
 		return
 	}
 
-	var logFile string
-	if logType == "stderr" {
-		logFile = proc.Config.StderrLogfile
+	var lines []string
+	for _, op := range ops {
+		line := fmt.Sprintf("%s  sudo %s", op.Time.Format("15:04:05"), op.Command)
+		if op.Err != nil {
+			line += fmt.Sprintf("  (failed: %v)", op.Err)
+		}
+		lines = append(lines, line)
+	}
+	m.showMessage("Elevated Operations", strings.Join(lines, "\n"))
+}
+
+// exportPrograms writes the marked (or selected) programs to a YAML/JSON file
+func (m *Model) exportPrograms(path string) (tea.Model, tea.Cmd) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return m, nil
+	}
+
+	var progs []*supervisor.ProcessConfig
+	for _, proc := range m.listModel.GetTargets() {
+		if proc.Config != nil {
+			progs = append(progs, proc.Config)
+		}
+	}
+	if len(progs) == 0 {
+		m.err = fmt.Errorf("no config loaded for the selected programs")
+		return m, nil
+	}
+
+	var notes []string
+	if stat, err := os.Stat(path); (err == nil && stat.IsDir()) || strings.HasSuffix(path, "/") {
+		// A directory receives one systemd unit per program
+		unitNotes, err := supervisor.ExportSystemdUnits(progs, path)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		notes = unitNotes
 	} else {
-		logFile = proc.Config.StdoutLogfile
+		data, exportNotes, err := supervisor.ExportPrograms(progs, supervisor.FormatFromPath(path))
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			m.err = fmt.Errorf("failed to write %s: %w", path, err)
+			return m, nil
+		}
+		notes = exportNotes
 	}
 
-	if logFile == "" {
-		return
+	status := fmt.Sprintf("Exported %d program(s) to %s", len(progs), path)
+	if len(notes) > 0 {
+		m.showMessage("Export Report", status+"\n\nSettings without an equivalent:\n"+strings.Join(notes, "\n"))
+		return m, nil
+	}
+	return m, m.setStatusMsg(status)
+}
+
+// importPrograms reads programs from a YAML/JSON file and asks to apply them
+func (m *Model) importPrograms(path string) (tea.Model, tea.Cmd) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.err = fmt.Errorf("failed to read %s: %w", path, err)
+		return m, nil
+	}
+
+	// Procfiles and systemd units are converted and reviewed in the editor
+	if supervisor.IsProcfile(path) || supervisor.IsSystemdUnit(path) {
+		return m.reviewGenerated(path, data)
+	}
+
+	progs, err := supervisor.ImportPrograms(data, supervisor.FormatFromPath(path))
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	changes := supervisor.PlanImport(m.config, progs)
+	var preview []string
+	pending := 0
+	for _, change := range changes {
+		switch change.Action {
+		case "create":
+			pending++
+			preview = append(preview, "+ "+change.Program.Name+" (new)")
+		case "change":
+			pending++
+			preview = append(preview, "~ "+change.Program.Name+" (changed)")
+		default:
+			preview = append(preview, "= "+change.Program.Name+" (unchanged)")
+		}
+	}
+	if pending == 0 {
+		return m, m.setStatusMsg("Import: nothing to do")
+	}
+
+	m.askConfirm("Confirm Import",
+		fmt.Sprintf("Apply %d change(s) from %s?\n\n%s", pending, path, strings.Join(preview, "\n")),
+		func() (tea.Model, tea.Cmd) { return m.applyImport(changes) })
+	return m, nil
+}
+
+// reviewGenerated converts a Procfile or systemd unit and opens the results in the editor
+func (m *Model) reviewGenerated(path string, data []byte) (tea.Model, tea.Cmd) {
+	m.drafts = nil
+
+	if supervisor.IsProcfile(path) {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			dir = filepath.Dir(path)
+		}
+		progs, warnings := supervisor.ParseProcfile(data, dir)
+		if len(progs) == 0 {
+			m.err = fmt.Errorf("%s: %s", path, strings.Join(warnings, "; "))
+			return m, nil
+		}
+		for _, prog := range progs {
+			m.drafts = append(m.drafts, draft{config: prog, warnings: draftWarnings(prog.Name, progs, warnings)})
+		}
+	} else {
+		prog, warnings, err := supervisor.ParseSystemdUnit(data, supervisor.UnitName(path))
+		if err != nil {
+			m.err = fmt.Errorf("%s: %w", path, err)
+			return m, nil
+		}
+		m.drafts = append(m.drafts, draft{config: prog, warnings: warnings})
+	}
+
+	m.draftTotal = len(m.drafts)
+	m.openNextDraft()
+	return m, nil
+}
+
+// draftWarnings returns the warnings that concern the generated program name
+// Warnings are prefixed with the program they belong to, others apply to all programs
+func draftWarnings(name string, progs []*supervisor.ProcessConfig, warnings []string) []string {
+	var result []string
+	for _, warning := range warnings {
+		owner := ""
+		for _, prog := range progs {
+			if strings.HasPrefix(warning, prog.Name+": ") {
+				owner = prog.Name
+				break
+			}
+		}
+		if owner == "" || owner == name {
+			result = append(result, warning)
+		}
+	}
+	return result
+}
+
+// openNextDraft opens the next generated config in the editor
+// It returns false when there is nothing left to review
+func (m *Model) openNextDraft() bool {
+	if len(m.drafts) == 0 {
+		return false
+	}
+
+	next := m.drafts[0]
+	m.drafts = m.drafts[1:]
+	title := fmt.Sprintf("Review Generated Process (%d of %d)", m.draftTotal-len(m.drafts), m.draftTotal)
+	m.editorModel.SetDraft(next.config, title, next.warnings)
+	m.mode = ModeAdd
+	return true
+}
+
+// applyImport writes imported programs and applies them
+func (m *Model) applyImport(changes []*supervisor.ImportChange) (tea.Model, tea.Cmd) {
+	names, err := supervisor.ApplyImport(changes)
+	if err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.applyImport(changes) }); ok {
+			return m, cmd
+		}
+		m.err = err
+		return m, nil
+	}
+
+	return m.finishImport(names)
+}
+
+// finishImport applies imported programs
+func (m *Model) finishImport(names []string) (tea.Model, tea.Cmd) {
+	// Reread config files and update imported processes
+	if err := m.applyConfig(names...); err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.finishImport(names) }); ok {
+			return m, cmd
+		}
+		m.err = err
+		return m, nil
+	}
+
+	m.refreshProcesses()
+	m.updateDetailView()
+	return m, m.setStatusMsg(fmt.Sprintf("Imported %d program(s)", len(names)))
+}
+
+// restoreHistory restores a config file to a recorded version and applies it
+func (m *Model) restoreHistory(entry *supervisor.HistoryEntry) (tea.Model, tea.Cmd) {
+	names, err := supervisor.RestoreHistoryEntry(entry)
+	if err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.restoreHistory(entry) }); ok {
+			return m, cmd
+		}
+		m.err = err
+		return m, nil
+	}
+
+	return m.finishRestore(entry, names)
+}
+
+// finishRestore applies a restored config file
+func (m *Model) finishRestore(entry *supervisor.HistoryEntry, names []string) (tea.Model, tea.Cmd) {
+	// Reread config files and update restored processes (adds, changes or removes them)
+	if err := m.applyConfig(names...); err != nil {
+		if cmd, ok := m.requestSudo(err, func() (tea.Model, tea.Cmd) { return m.finishRestore(entry, names) }); ok {
+			return m, cmd
+		}
+		m.err = err
+		return m, nil
+	}
+
+	m.refreshProcesses()
+	m.updateDetailView()
+	return m, m.setStatusMsg(fmt.Sprintf("Restored %s", entry.Path))
+}
+
+// openLogs shows a log stream of a process in the log viewer
+func (m *Model) openLogs(proc *supervisor.Process, stream string) tea.Cmd {
+	m.logViewer.Open(proc, stream)
+	m.mode = ModeViewLogs
+	m.logTicks++
+	return m.logTick()
+}
+
+// logTick returns a command that reloads the log viewer after a delay
+func (m *Model) logTick() tea.Cmd {
+	generation := m.logTicks
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return logTickMsg{generation: generation}
+	})
+}
+
+// editLogs opens the log file shown in the viewer in $EDITOR,
+// handing the terminal over until the editor exits
+func (m *Model) editLogs() tea.Cmd {
+	path := m.logViewer.Path()
+	if path == "" {
+		return nil
 	}
 
 	// Get editor from environment or default to vi
@@ -671,14 +1672,9 @@ func (m *Model) viewLogs(proc *supervisor.Process, logType string) {
 		editor = "vi"
 	}
 
-	cmd := exec.Command(editor, logFile)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	// Note: This will block the TUI, but that's expected behavior
-	// The user will see the editor and can exit to return to the TUI
-	cmd.Run()
+	return tea.ExecProcess(exec.Command(editor, path), func(err error) tea.Msg {
+		return editorFinishedMsg{err: err}
+	})
 }
 
 // View renders the model
@@ -690,6 +1686,20 @@ func (m *Model) View() string {
 		return m.renderEditor()
 	case ModeDelete:
 		return m.renderDeleteConfirm()
+	case ModeHistory:
+		return m.renderHistory()
+	case ModeTemplate:
+		return m.renderTemplates()
+	case ModeViewLogs:
+		return m.renderLogViewer()
+	case ModeMergedLogs:
+		return m.renderMergedLogs()
+	case ModeConfirm:
+		return m.renderConfirm()
+	case ModePrompt:
+		return m.renderPrompt()
+	case ModeMessage:
+		return m.renderMessage()
 	default:
 		return m.renderList()
 	}
@@ -707,10 +1717,15 @@ func (m *Model) renderList() string {
 	)
 	content = lipgloss.NewStyle().MarginTop(1).Width(m.width).Render(content)
 
-	// Shorten status bar for smaller screens
-	statusText := "j/k: nav | /: search | s: start | x: stop | r: restart | a: add | e: edit | d: del | l: stdout | L: stderr | q: quit"
-	if m.width < 100 {
-		statusText = "j/k: nav | s: start | x: stop | r: restart | a: add | e: edit | d: del | l/L: logs | q: quit"
+	// Shorten status bar for smaller screens, ? lists all keys
+	statusText := "j/k: nav | /: search | s/x/r: start/stop/restart | a: add | e/E: edit | c: clone | d: del | space: mark | l/L/M: logs | ?: keys | q: quit"
+	if m.width < 140 {
+		statusText = "j/k: nav | s: start | x: stop | r: restart | a: add | e: edit | l/L: logs | ?: keys | q: quit"
+	}
+
+	// Remind about config changes on disk that haven't been applied
+	if len(m.pending) > 0 {
+		statusText = pendingStyle.Render(fmt.Sprintf("%d pending change(s), u: apply", len(m.pending))) + " | " + statusText
 	}
 
 	// Add status message if present
@@ -718,6 +1733,11 @@ func (m *Model) renderList() string {
 		statusText = m.statusMsg + " | " + statusText
 	}
 
+	// The latest alert stays until the alerts are looked at
+	if banner := m.alertWatcher.alertBanner(m.width / 2); banner != "" {
+		statusText = banner + " | " + statusText
+	}
+
 	status := lipgloss.NewStyle().
 		Foreground(fgColor).
 		Padding(0, 1).
@@ -794,6 +1814,66 @@ func (m *Model) renderDeleteConfirm() string {
 	)
 }
 
+// renderHistory renders the config history view
+func (m *Model) renderHistory() string {
+	return "\n" + lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Top, m.historyModel.View())
+}
+
+// renderLogViewer renders the full-screen log viewer
+func (m *Model) renderLogViewer() string {
+	return "\n" + lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Top, m.logViewer.View())
+}
+
+// renderMergedLogs renders the merged logs of the marked programs
+func (m *Model) renderMergedLogs() string {
+	return "\n" + lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Top, m.mergedModel.View())
+}
+
+// renderTemplates renders the template picker
+func (m *Model) renderTemplates() string {
+	return "\n" + lipgloss.Place(m.width, m.height-1, lipgloss.Center, lipgloss.Top, m.templateModel.View())
+}
+
+// renderConfirm renders a generic yes/no confirmation view
+func (m *Model) renderConfirm() string {
+	if m.confirm == nil {
+		return ""
+	}
+
+	return detailPanelStyle.Width(m.width - 4).Height(10).Render(
+		titleStyle.Render(m.confirm.title) + "\n\n" +
+			warningStyle.Render(m.confirm.message) + "\n\n" +
+			helpStyle.Render("y: confirm | n/Esc: cancel"),
+	)
+}
+
+// renderMessage renders an informational message
+func (m *Model) renderMessage() string {
+	lines := strings.Split(m.message, "\n")
+	for i, line := range lines {
+		lines[i] = valueStyle.Render(truncateLine(line, m.width-10))
+	}
+
+	return detailPanelStyle.Width(m.width - 4).Render(
+		titleStyle.Render(m.messageTitle) + "\n\n" +
+			strings.Join(lines, "\n") + "\n\n" +
+			helpStyle.Render("Enter/Esc: close"),
+	)
+}
+
+// renderPrompt renders a single-line input prompt
+func (m *Model) renderPrompt() string {
+	if m.prompt == nil {
+		return ""
+	}
+
+	return detailPanelStyle.Width(m.width - 4).Height(8).Render(
+		titleStyle.Render(m.prompt.title) + "\n\n" +
+			m.prompt.input.View() + "\n\n" +
+			helpStyle.Render("Enter: confirm | Esc: cancel"),
+	)
+}
+
 // setStatusMsg sets a temporary status message that will be cleared after 3 seconds
 func (m *Model) setStatusMsg(msg string) tea.Cmd {
 	m.statusMsg = msg
@@ -803,6 +1883,30 @@ func (m *Model) setStatusMsg(msg string) tea.Cmd {
 	})
 }
 
+// historyNotice reports a config change that couldn't be recorded in history
+func (m *Model) historyNotice() tea.Cmd {
+	err := supervisor.TakeHistoryError()
+	if err == nil {
+		return nil
+	}
+	return m.setStatusMsg(fmt.Sprintf("Change saved, but not recorded in history: %v", err))
+}
+
+// processActionAsync runs a start, stop or restart action asynchronously
+func (m *Model) processActionAsync(action, name string) tea.Cmd {
+	switch action {
+	case "start":
+		m.pendingActions[name] = "STARTING"
+		return m.startProcessAsync(name)
+	case "stop":
+		m.pendingActions[name] = "STOPPING"
+		return m.stopProcessAsync(name)
+	default:
+		m.pendingActions[name] = "RESTARTING"
+		return m.restartProcessAsync(name)
+	}
+}
+
 // startProcessAsync starts a process asynchronously
 func (m *Model) startProcessAsync(name string) tea.Cmd {
 	return func() tea.Msg {
@@ -95,6 +95,10 @@ var (
 				Foreground(subtleColor).
 				Bold(true)
 
+	// Config changed on disk but not applied yet
+	pendingStyle = lipgloss.NewStyle().
+			Foreground(warningColor)
+
 	// Editor styles
 	inputStyle = lipgloss.NewStyle().
 			Foreground(fgColor).
@@ -0,0 +1,327 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	tailBlockSize   = 64 * 1024       // Bytes read per step when reading backwards
+	maxLineBytes    = 64 * 1024       // Longer lines are clipped to this length
+	maxAppendBytes  = 8 * 1024 * 1024 // Larger appends are re-read from the end instead
+	maxTailBytes    = 4 * 1024 * 1024 // Bytes read at most when reading backwards from the end
+	checkBytes      = 64              // Bytes before the offset compared to notice a rewritten file
+	clippedLineMark = " …[clipped]"
+	rotatedMark     = "— log rotated —"
+	truncatedMark   = "— log truncated —"
+	skippedMark     = "— lines skipped —"
+)
+
+// tailReader keeps the last lines of a file up to date
+// The first read seeks to the end and reads backwards in blocks, later reads
+// only read what was appended since. Nothing is read if size and mtime are unchanged.
+// When the file is rotated or truncated, the reader continues with the new
+// content after a marker line, so following a log survives it.
+type tailReader struct {
+	path    string
+	limit   int // Maximum number of lines kept
+	size    int64
+	modTime time.Time
+	info    os.FileInfo // File last read, to notice when path is a new file
+	offset  int64       // Offset up to which the file has been read
+	partial []byte      // Text after the last newline, completed by a later read
+	before  []byte      // The last bytes read, up to offset
+	lines   []string
+	loaded  bool
+	total   int // Complete lines added since the last full read
+	resets  int // Number of full reads, after which lines start over
+}
+
+// newTailReader creates a reader keeping the last limit lines of path
+func newTailReader(path string, limit int) *tailReader {
+	return &tailReader{path: path, limit: limit}
+}
+
+// Read brings the lines up to date with the file
+// It returns true if the lines changed
+func (t *tailReader) Read() (bool, error) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return false, err
+	}
+	rotated := t.loaded && t.info != nil && !os.SameFile(t.info, info)
+	if t.loaded && !rotated && info.Size() == t.size && info.ModTime().Equal(t.modTime) {
+		return false, nil
+	}
+
+	// Compressed backups can't be read backwards and don't grow: read them whole
+	if strings.HasSuffix(t.path, ".gz") {
+		if err := t.readCompressed(); err != nil {
+			return false, err
+		}
+		t.size = info.Size()
+		t.modTime = info.ModTime()
+		t.info = info
+		t.loaded = true
+		return true, nil
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	size := info.Size()
+	switch {
+	case !t.loaded:
+		err = t.readTail(file, size)
+	case rotated:
+		// Lines written to the old file before it was renamed come first
+		t.readRotated()
+		err = t.reopen(file, size, rotatedMark)
+	case size < t.offset || t.rewritten(file):
+		// Truncated, possibly written again past the offset since
+		err = t.reopen(file, size, truncatedMark)
+	case size-t.offset > maxAppendBytes:
+		err = t.readLargeAppend(file, size, t.offset, skippedMark)
+	default:
+		err = t.readAppended(file, size)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	t.size = size
+	t.modTime = info.ModTime()
+	t.info = info
+	t.loaded = true
+	return true, nil
+}
+
+// Lines returns the lines read so far, including an unterminated last line
+func (t *tailReader) Lines() []string {
+	if len(t.partial) == 0 {
+		return t.lines
+	}
+	lines := append(t.lines[:len(t.lines):len(t.lines)], clipLine(t.partial))
+	if len(lines) > t.limit {
+		lines = lines[len(lines)-t.limit:]
+	}
+	return lines
+}
+
+// readTail reads the last lines of the file backwards from size in blocks
+func (t *tailReader) readTail(file *os.File, size int64) error {
+	data, err := t.readBackwards(file, size, 0)
+	if err != nil {
+		return err
+	}
+
+	t.lines = nil
+	t.partial = nil
+	t.offset = size
+	t.before = lastBytes(nil, data)
+	t.total = 0
+	t.resets++
+	t.appendData(data)
+	return nil
+}
+
+// readLargeAppend reads only the end of what was written after from, when it
+// is too much to read whole. The lines count as appended after a marker line,
+// rather than starting over like a full read, so readers know they are new.
+func (t *tailReader) readLargeAppend(file *os.File, size, from int64, mark string) error {
+	data, err := t.readBackwards(file, size, from)
+	if err != nil {
+		return err
+	}
+
+	if len(t.partial) > 0 {
+		t.appendData([]byte{'\n'})
+	}
+	t.appendData([]byte(mark + "\n"))
+	t.offset = size
+	t.before = lastBytes(nil, data)
+	t.appendData(data)
+	return nil
+}
+
+// readBackwards returns the last lines of the file between from and size,
+// reading backwards in blocks. At most maxTailBytes are read, so fewer lines
+// are returned if they are very long.
+func (t *tailReader) readBackwards(file *os.File, size, from int64) ([]byte, error) {
+	var blocks [][]byte // Last block first
+	newlines := 0
+	pos := size
+	// Stop once there is one more newline than lines wanted (the first line
+	// may be incomplete), or once the budget is used up
+	for pos > from && newlines <= t.limit && size-pos < maxTailBytes {
+		step := min(int64(tailBlockSize), pos-from)
+		pos -= step
+		block := make([]byte, step)
+		if _, err := file.ReadAt(block, pos); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read %s: %w", t.path, err)
+		}
+		blocks = append(blocks, block)
+		newlines += bytes.Count(block, []byte{'\n'})
+	}
+	data := make([]byte, 0, size-pos)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		data = append(data, blocks[i]...)
+	}
+
+	// The text before the first newline is only a whole line where reading started
+	if pos > from {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	return data, nil
+}
+
+// readAppended reads what was appended to the file since the last read
+func (t *tailReader) readAppended(file *os.File, size int64) error {
+	data := make([]byte, size-t.offset)
+	if _, err := file.ReadAt(data, t.offset); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read %s: %w", t.path, err)
+	}
+	t.offset = size
+	t.before = lastBytes(t.before, data)
+	t.appendData(data)
+	return nil
+}
+
+// rewritten returns true if the bytes before the offset changed since they were read
+func (t *tailReader) rewritten(file *os.File) bool {
+	if len(t.before) == 0 {
+		return false
+	}
+	data := make([]byte, len(t.before))
+	if _, err := file.ReadAt(data, t.offset-int64(len(data))); err != nil {
+		return true
+	}
+	return !bytes.Equal(data, t.before)
+}
+
+// lastBytes returns the last checkBytes bytes of prev followed by data
+func lastBytes(prev, data []byte) []byte {
+	if len(data) >= checkBytes {
+		return append([]byte{}, data[len(data)-checkBytes:]...)
+	}
+	joined := append(append([]byte{}, prev...), data...)
+	return joined[max(0, len(joined)-checkBytes):]
+}
+
+// readRotated reads what was appended to the file before it was rotated,
+// if it can still be found next to the log, e.g. renamed to app.log.1
+func (t *tailReader) readRotated() {
+	old := findRotated(t.path, t.info)
+	if old == "" {
+		return
+	}
+	file, err := os.Open(old)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.Size() < t.offset || info.Size()-t.offset > maxAppendBytes {
+		return
+	}
+	t.readAppended(file, info.Size())
+}
+
+// findRotated returns the backup of path that is the file last read, empty if there is none
+func findRotated(path string, last os.FileInfo) string {
+	dotted, _ := filepath.Glob(globEscape(path) + ".*")
+	dated, _ := filepath.Glob(globEscape(path) + "-*")
+	for _, match := range append(dotted, dated...) {
+		if strings.HasSuffix(match, ".gz") {
+			continue
+		}
+		if info, err := os.Stat(match); err == nil && os.SameFile(info, last) {
+			return match
+		}
+	}
+	return ""
+}
+
+// reopen continues with a new or truncated file from its start, after a marker line
+// A file that already grew too large is read from the end instead.
+func (t *tailReader) reopen(file *os.File, size int64, mark string) error {
+	if len(t.partial) > 0 {
+		t.appendData([]byte{'\n'})
+	}
+	if size > maxAppendBytes {
+		return t.readLargeAppend(file, size, 0, mark)
+	}
+	t.appendData([]byte(mark + "\n"))
+	t.offset = 0
+	t.before = nil
+	return t.readAppended(file, size)
+}
+
+// readCompressed decompresses the whole file, keeping the last lines
+func (t *tailReader) readCompressed() error {
+	t.lines = nil
+	t.partial = nil
+	t.total = 0
+	t.resets++
+	err := scanLogFile(t.path, func(line string) bool {
+		t.lines = append(t.lines, line)
+		t.total++
+		if len(t.lines) >= 2*t.limit {
+			t.lines = append([]string{}, t.lines[len(t.lines)-t.limit:]...)
+		}
+		return true
+	})
+	if len(t.lines) > t.limit {
+		t.lines = append([]string{}, t.lines[len(t.lines)-t.limit:]...)
+	}
+	return err
+}
+
+// appendData splits data into lines and adds them, keeping at most limit lines
+func (t *tailReader) appendData(data []byte) {
+	if len(t.partial) > 0 {
+		data = append(t.partial, data...)
+		t.partial = nil
+	}
+
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		t.lines = append(t.lines, clipLine(data[:i]))
+		t.total++
+		data = data[i+1:]
+	}
+	if len(data) > 0 {
+		// Keep at most one clipped line's worth of an unterminated line
+		if len(data) > maxLineBytes {
+			data = data[:maxLineBytes]
+		}
+		t.partial = append([]byte{}, data...)
+	}
+
+	if len(t.lines) > t.limit {
+		t.lines = append([]string{}, t.lines[len(t.lines)-t.limit:]...)
+	}
+}
+
+// clipLine converts a line to a string, clipping very long lines and
+// removing escape sequences that would corrupt the screen
+func clipLine(line []byte) string {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) > maxLineBytes {
+		return sanitizeLine(strings.ToValidUTF8(string(line[:maxLineBytes]), "")) + clippedLineMark
+	}
+	return sanitizeLine(string(line))
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nicklasos/supervisord-tui/internal/supervisor"
+)
+
+// TemplateModel lets the user pick a program template and fill in its placeholders
+type TemplateModel struct {
+	templates []supervisor.Template
+	selected  int
+	filling   bool // Showing the placeholder form instead of the template list
+	fields    []string
+	inputs    []textinput.Model
+	focused   int
+	width     int
+	height    int
+}
+
+// NewTemplateModel creates a new template model
+func NewTemplateModel() *TemplateModel {
+	return &TemplateModel{}
+}
+
+// Load reloads the available templates and shows the picker
+func (m *TemplateModel) Load() {
+	m.templates = supervisor.LoadTemplates()
+	m.selected = 0
+	m.filling = false
+	m.focused = 0
+}
+
+// SetSize sets the size of the template view
+func (m *TemplateModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Filling returns true while the placeholder form is shown
+func (m *TemplateModel) Filling() bool {
+	return m.filling
+}
+
+// Pick opens the placeholder form for the selected template
+func (m *TemplateModel) Pick() tea.Cmd {
+	if m.selected < 0 || m.selected >= len(m.templates) {
+		return nil
+	}
+
+	names, defaults := m.templates[m.selected].Placeholders()
+	m.fields = names
+	m.inputs = make([]textinput.Model, len(names))
+	for i, name := range names {
+		input := textinput.New()
+		input.Prompt = ""
+		input.SetValue(defaults[name])
+		input.CursorEnd()
+		m.inputs[i] = input
+	}
+	m.filling = true
+	m.focused = 0 // The previous form may have had more fields
+	return m.focus(0)
+}
+
+// Back returns from the placeholder form to the template list
+func (m *TemplateModel) Back() {
+	m.filling = false
+}
+
+// Render returns the selected template with the form values filled in
+func (m *TemplateModel) Render() string {
+	vars := make(map[string]string)
+	for i, name := range m.fields {
+		vars[name] = strings.TrimSpace(m.inputs[i].Value())
+	}
+	return supervisor.RenderTemplate(m.templates[m.selected].Text, vars)
+}
+
+// focus moves the input focus to field i
+func (m *TemplateModel) focus(i int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = (i + len(m.inputs)) % len(m.inputs)
+	return m.inputs[m.focused].Focus()
+}
+
+// Update handles updates to the template model
+func (m *TemplateModel) Update(msg tea.Msg) (*TemplateModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if !m.filling {
+		switch keyMsg.String() {
+		case "j", "down":
+			if m.selected < len(m.templates)-1 {
+				m.selected++
+			}
+		case "k", "up":
+			if m.selected > 0 {
+				m.selected--
+			}
+		}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "tab", "down":
+		return m, m.focus(m.focused + 1)
+	case "shift+tab", "up":
+		return m, m.focus(m.focused - 1)
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return m, cmd
+}
+
+// View renders the template picker or the placeholder form
+func (m *TemplateModel) View() string {
+	var lines []string
+
+	if !m.filling {
+		lines = append(lines, titleStyle.Render("New Process: Choose a Template"))
+		for i, tmpl := range m.templates {
+			source := "built-in"
+			if tmpl.Path != "" {
+				source = tmpl.Path
+			}
+			row := truncateLine(fmt.Sprintf("%-20s %s", tmpl.Name, source), m.width-8)
+			if i == m.selected {
+				lines = append(lines, listItemSelectedStyle.Render("▶ "+row))
+			} else {
+				lines = append(lines, listItemStyle.Render("  "+row))
+			}
+		}
+		lines = append(lines, "",
+			labelStyle.Render(fmt.Sprintf("Add your own as %s/<name>.conf", supervisor.TemplatesDir())),
+			"", helpStyle.Render("j/k: select | Enter: use template | Esc: cancel"))
+		return detailPanelStyle.Width(m.width).Height(m.height).Render(strings.Join(lines, "\n"))
+	}
+
+	lines = append(lines, titleStyle.Render(fmt.Sprintf("New Process: %s", m.templates[m.selected].Name)))
+	for i, name := range m.fields {
+		label := labelStyle.Render(fmt.Sprintf("%-12s", name))
+		if i == m.focused {
+			label = titleStyle.UnsetMarginBottom().Render(fmt.Sprintf("%-12s", name))
+		}
+		lines = append(lines, label+" "+m.inputs[i].View())
+	}
+	lines = append(lines, "", helpStyle.Render("Tab/Shift+Tab: field | Enter: open in editor | Esc: back"))
+
+	return detailPanelStyle.Width(m.width).Height(m.height).Render(strings.Join(lines, "\n"))
+}
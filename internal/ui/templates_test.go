@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickTemplate selects the named template in the picker and opens its form
+func pickTemplate(t *testing.T, m *TemplateModel, name string) {
+	t.Helper()
+	m.Back()
+	m.selected = -1
+	for i, tmpl := range m.templates {
+		if tmpl.Name == name {
+			m.selected = i
+		}
+	}
+	if m.selected < 0 {
+		t.Fatalf("no template named %s", name)
+	}
+	m.Pick()
+}
+
+func TestTemplatePickSmallerAfterLarger(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := NewTemplateModel()
+	m.Load()
+
+	pickTemplate(t, m, "node-web")
+	for i := 0; i < len(m.inputs)-1; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	if m.fields[m.focused] != "port" {
+		t.Fatalf("focused %s, want port", m.fields[m.focused])
+	}
+
+	pickTemplate(t, m, "python-worker")
+	if m.focused != 0 {
+		t.Errorf("focused field %d after picking another template, want 0", m.focused)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m.View()
+}
+
+func TestTemplateAsksForEnvironment(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := NewTemplateModel()
+	m.Load()
+
+	pickTemplate(t, m, "default")
+	for _, field := range m.fields {
+		if field == "environment" {
+			return
+		}
+	}
+	t.Errorf("default template fields %v don't include environment", m.fields)
+}
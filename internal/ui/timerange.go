@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// timeRangeMsg carries the lines of a time range collected from the log and its backups
+type timeRangeMsg struct {
+	spec      string
+	lines     []string
+	truncated bool
+	err       error
+}
+
+// lineTimes returns the time of each line; lines without a timestamp, like
+// stack traces, get the time of the line before them
+func lineTimes(lines []string) []time.Time {
+	times := make([]time.Time, len(lines))
+	var last time.Time
+	for i, line := range lines {
+		if t, ok := lineTime(line); ok {
+			last = t
+		}
+		times[i] = last
+	}
+	return times
+}
+
+// sourceTimes returns the times of the lines the filters are applied to
+// They are only computed while a time range is used.
+func (m *LogViewerModel) sourceTimes() []time.Time {
+	if m.rangeLines != nil {
+		return m.rangeTimes
+	}
+	if m.times == nil || len(m.times) != len(m.all) {
+		m.times = lineTimes(m.all)
+	}
+	return m.times
+}
+
+// inTimeRange returns true if a line time is within the time range
+func (m *LogViewerModel) inTimeRange(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	if !m.timeFrom.IsZero() && t.Before(m.timeFrom) {
+		return false
+	}
+	return m.timeTo.IsZero() || t.Before(m.timeTo)
+}
+
+// jumpToTime moves to the first shown line at or after a typed time
+func (m *LogViewerModel) jumpToTime(text string) {
+	target, _, err := parseTimePoint(text, time.Now())
+	if err != nil {
+		m.notice = err.Error()
+		return
+	}
+
+	times := lineTimes(m.lines)
+	idx := -1
+	for i, t := range times {
+		if !t.IsZero() && !t.Before(target) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		m.notice = fmt.Sprintf("No lines at or after %s", target.Format("2006-01-02 15:04:05"))
+		return
+	}
+
+	// Older lines may only be in the backups
+	if first := times[idx]; idx == firstTimed(times) && first.Sub(target) > time.Minute {
+		m.notice = fmt.Sprintf("The oldest line shown is from %s, T loads a time range from the backups", first.Format("2006-01-02 15:04:05"))
+	}
+	m.hit = idx
+	m.follow = false
+	m.offset = max(0, min(idx-m.pageHeight()/2, m.maxOffset()))
+}
+
+// firstTimed returns the index of the first line with a time, -1 if there is none
+func firstTimed(times []time.Time) int {
+	for i, t := range times {
+		if !t.IsZero() {
+			return i
+		}
+	}
+	return -1
+}
+
+// setTimeRange restricts the shown lines to a time range, clearing it if text is empty
+// Ranges starting before the oldest line read are collected from the log and
+// its rotated backups in the background.
+func (m *LogViewerModel) setTimeRange(text string) tea.Cmd {
+	text = strings.TrimSpace(text)
+	m.clearTimeRange()
+	if text == "" {
+		m.applyFilter()
+		return nil
+	}
+
+	from, to, err := parseTimeRange(text, time.Now())
+	if err != nil {
+		m.notice = err.Error()
+		m.applyFilter()
+		return nil
+	}
+	m.timeText = text
+	m.timeFrom = from
+	m.timeTo = to
+	m.hit = -1
+
+	// The lines read cover the range if they start before it
+	times := m.sourceTimes()
+	if i := firstTimed(times); i >= 0 && !from.IsZero() && !times[i].After(from) {
+		m.applyFilter()
+		return nil
+	}
+
+	path := m.logPath()
+	if path == "" {
+		m.applyFilter()
+		return nil
+	}
+	m.rangeLoading = true
+	m.applyFilter()
+	return func() tea.Msg {
+		lines, truncated, err := collectTimeRange(findLogFiles(path), from, to)
+		return timeRangeMsg{spec: text, lines: lines, truncated: truncated, err: err}
+	}
+}
+
+// showTimeRange shows the lines collected for the time range
+func (m *LogViewerModel) showTimeRange(msg timeRangeMsg) {
+	if msg.spec != m.timeText || !m.rangeLoading {
+		return
+	}
+	m.rangeLoading = false
+	if msg.err != nil {
+		m.notice = msg.err.Error()
+	} else if msg.truncated {
+		m.notice = fmt.Sprintf("Showing the first %d lines of the range", viewerMaxLines)
+	}
+	m.rangeLines = msg.lines
+	m.rangeLevels = classifyLines(msg.lines)
+	m.rangeTimes = lineTimes(msg.lines)
+	m.follow = false
+	m.offset = 0
+	m.applyFilter()
+}
+
+// clearTimeRange stops restricting the shown lines to a time range
+func (m *LogViewerModel) clearTimeRange() {
+	m.timeText = ""
+	m.timeFrom = time.Time{}
+	m.timeTo = time.Time{}
+	m.rangeLines = nil
+	m.rangeLevels = nil
+	m.rangeTimes = nil
+	m.rangeLoading = false
+}
+
+// timeRangeStatus describes the time range for the status line
+func (m *LogViewerModel) timeRangeStatus() string {
+	status := "time: " + m.timeText
+	switch {
+	case m.rangeLoading:
+		status += " (reading backups...)"
+	case m.rangeLines != nil:
+		status += fmt.Sprintf(" (%d lines from the log and its backups)", len(m.lines))
+	default:
+		status += fmt.Sprintf(" (%d lines)", len(m.lines))
+	}
+	return status
+}
+
+// collectTimeRange reads the lines within a time range from log files, oldest file first
+// Files whose time range doesn't overlap are skipped. At most viewerMaxLines lines are kept.
+func collectTimeRange(files []logFile, from, to time.Time) ([]string, bool, error) {
+	var lines []string
+	var firstErr error
+	for i := len(files) - 1; i >= 0; i-- {
+		f := files[i]
+		if !from.IsZero() && !f.last.IsZero() && f.last.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !f.first.IsZero() && !f.first.Before(to) {
+			break
+		}
+
+		var current time.Time
+		past := false
+		err := scanLogFile(f.path, func(line string) bool {
+			if t, ok := lineTime(line); ok {
+				current = t
+			}
+			if current.IsZero() || (!from.IsZero() && current.Before(from)) {
+				return true
+			}
+			if !to.IsZero() && !current.Before(to) {
+				past = true
+				return false
+			}
+			lines = append(lines, line)
+			return len(lines) < viewerMaxLines
+		})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if len(lines) >= viewerMaxLines {
+			return lines, true, firstErr
+		}
+		if past {
+			break
+		}
+	}
+	return lines, false, firstErr
+}
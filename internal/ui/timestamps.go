@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the timestamp formats recognized at the start of log lines
+var timestampLayouts = []struct {
+	re     *regexp.Regexp
+	layout string
+}{
+	// 2024-01-02T15:04:05Z, 2024-01-02T15:04:05.123+01:00
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})`), time.RFC3339Nano},
+	// 2024-01-02T15:04:05
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}`), "2006-01-02T15:04:05"},
+	// 2024-01-02 15:04:05,123 (supervisord, Python logging)
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2},\d{3}`), "2006-01-02 15:04:05,000"},
+	// 2024-01-02 15:04:05.123
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\.\d+`), "2006-01-02 15:04:05.999999999"},
+	// 2024-01-02 15:04:05
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`), "2006-01-02 15:04:05"},
+	// 2024/01/02 15:04:05 (Go log package)
+	{regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`), "2006/01/02 15:04:05"},
+	// 02/Jan/2024:15:04:05 -0700 (access logs)
+	{regexp.MustCompile(`^\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`), "02/Jan/2006:15:04:05 -0700"},
+	// Jan  2 15:04:05 (syslog, no year)
+	{regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`), time.Stamp},
+}
+
+// epochRe matches seconds or milliseconds since the epoch at the start of a line
+var epochRe = regexp.MustCompile(`^(\d{10}|\d{13})(\.\d{1,9})?(\s|$)`)
+
+// lineTime returns the timestamp a log line starts with, or the time field of a JSON line
+// Leading spaces and brackets, as in "[2024-01-02 15:04:05]", are skipped.
+func lineTime(line string) (time.Time, bool) {
+	if entry, ok := parseJSONLine(line); ok {
+		return entry.Time()
+	}
+	return parseTimestamp(strings.TrimLeft(plainText(line), " ["))
+}
+
+// parseTimestamp parses the timestamp text starts with
+func parseTimestamp(text string) (time.Time, bool) {
+	for _, ts := range timestampLayouts {
+		match := ts.re.FindString(text)
+		if match == "" {
+			continue
+		}
+		t, err := time.ParseInLocation(ts.layout, match, time.Local)
+		if err != nil {
+			continue
+		}
+		// Syslog timestamps have no year: assume the most recent one
+		if t.Year() == 0 {
+			now := time.Now()
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+		}
+		return t, true
+	}
+
+	// Epoch times are only taken for plausible dates, other numbers are ids or counters
+	if match := epochRe.FindString(text); match != "" {
+		if epoch, err := strconv.ParseFloat(strings.TrimSpace(match), 64); err == nil {
+			if epoch > 1e11 {
+				epoch /= 1000
+			}
+			if epoch >= minEpoch && epoch < maxEpoch {
+				sec := int64(epoch)
+				return time.Unix(sec, int64((epoch-float64(sec))*1e9)), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Epoch times at the start of lines are taken for years 2000 to 2100
+const (
+	minEpoch = 946684800
+	maxEpoch = 4102444800
+)
+
+// timePointLayouts are the layouts accepted when typing a time, with their precision
+var timePointLayouts = []struct {
+	layout    string
+	precision time.Duration
+}{
+	{time.RFC3339, time.Second},
+	{"2006-01-02T15:04:05", time.Second},
+	{"2006-01-02 15:04:05", time.Second},
+	{"2006-01-02T15:04", time.Minute},
+	{"2006-01-02 15:04", time.Minute},
+	{"2006-01-02", 24 * time.Hour},
+	{"15:04:05", time.Second},
+	{"15:04", time.Minute},
+}
+
+// parseTimePoint parses a typed time: a date and time, a time of day (the most
+// recent one) or a duration ago like "10m ago". It returns the time and its
+// precision, e.g. a minute for "14:05".
+func parseTimePoint(text string, now time.Time) (time.Time, time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if ago, ok := strings.CutSuffix(text, " ago"); ok {
+		d, err := parseSpan(ago)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		return now.Add(-d), time.Second, nil
+	}
+
+	for _, p := range timePointLayouts {
+		t, err := time.ParseInLocation(p.layout, text, time.Local)
+		if err != nil {
+			continue
+		}
+		// A time of day is today's, or yesterday's if that's still to come
+		if t.Year() == 0 {
+			t = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+			if t.After(now.Add(time.Minute)) {
+				t = t.AddDate(0, 0, -1)
+			}
+		}
+		return t, p.precision, nil
+	}
+	return time.Time{}, 0, fmt.Errorf("unknown time %q, use 14:05, 2024-01-02 14:05 or 10m ago", text)
+}
+
+// parseTimeRange parses a range like "14:00..14:15", "2024-01-02 09:00..",
+// "..14:15", "last 10m" or a single time, which covers its precision ("14:05"
+// is the whole minute). Open ends are returned as zero times; to is exclusive.
+func parseTimeRange(text string, now time.Time) (time.Time, time.Time, error) {
+	text = strings.TrimSpace(text)
+	if span, ok := strings.CutPrefix(text, "last "); ok {
+		d, err := parseSpan(span)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return now.Add(-d), time.Time{}, nil
+	}
+
+	start, end, isRange := strings.Cut(text, "..")
+	if !isRange {
+		t, precision, err := parseTimePoint(text, now)
+		return t, t.Add(precision), err
+	}
+
+	var from, to time.Time
+	if start = strings.TrimSpace(start); start != "" {
+		t, _, err := parseTimePoint(start, now)
+		if err != nil {
+			return from, to, err
+		}
+		from = t
+	}
+	if end = strings.TrimSpace(end); end != "" {
+		// The end includes its whole minute or day
+		t, precision, err := parseTimePoint(end, now)
+		if err != nil {
+			return from, to, err
+		}
+		to = t.Add(precision)
+	}
+	if from.IsZero() && to.IsZero() {
+		return from, to, fmt.Errorf("empty time range")
+	}
+	// A range of times of day may start yesterday: 23:50..00:10
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		if len(start) <= len("15:04:05") {
+			from = from.AddDate(0, 0, -1)
+		} else {
+			return from, to, fmt.Errorf("the range ends before it starts")
+		}
+	}
+	return from, to, nil
+}
+
+// parseSpan parses a duration like "10m", "2h30m" or "3d"
+func parseSpan(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("unknown duration %q, use 10m, 2h or 3d", text)
+	}
+	return d, nil
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nicklasos/supervisord-tui/internal/ui"
@@ -12,8 +13,21 @@ import (
 var version = "dev"
 
 func main() {
+	// Subcommands run without the TUI
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+	}
+
 	showVersion := flag.Bool("version", false, "Show version information")
 	configPath := flag.String("config", "", "Path to supervisord config file (default: auto-detect)")
+	noColor := flag.Bool("no-color", false, "Strip ANSI colors from program logs (also set by NO_COLOR)")
+	errorWindow := flag.Duration("error-window", 5*time.Minute, "How far back errors are counted in the process list")
 	flag.Parse()
 
 	if *showVersion {
@@ -21,6 +35,12 @@ func main() {
 		os.Exit(0)
 	}
 
+	// https://no-color.org: any non-empty value disables colors
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		ui.SetColorLogs(false)
+	}
+	ui.SetErrorWindow(*errorWindow)
+
 	var model *ui.Model
 	var err error
 	if *configPath != "" {